@@ -0,0 +1,101 @@
+package bioportal
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+type AnalyticsOptions struct {
+	// Year and Month restrict the visits to a single month. Both are
+	// optional. Month is a number from 1 to 12, such as int(time.March).
+	Year  int `url:"year,omitempty"`
+	Month int `url:"month,omitempty"`
+
+	// Ontologies restricts the results to these acronyms.
+	Ontologies []string `url:"ontologies,comma,omitempty"`
+}
+
+type MonthlyVisits struct {
+	Year   int
+	Month  time.Month
+	Visits int
+}
+
+// OntologyAnalytics holds the visits of an ontology's pages in BioPortal.
+// Visits are only counted per ontology, not per class.
+type OntologyAnalytics struct {
+	Ontology string
+
+	// Visits are ordered by month, oldest first.
+	Visits []MonthlyVisits
+}
+
+// Total returns the number of visits across all months.
+func (a *OntologyAnalytics) Total() int {
+	var n int
+	for _, v := range a.Visits {
+		n += v.Visits
+	}
+	return n
+}
+
+// Since returns the number of visits from the given month onwards.
+func (a *OntologyAnalytics) Since(year int, month time.Month) int {
+	var n int
+	for _, v := range a.Visits {
+		if v.Year > year || (v.Year == year && v.Month >= month) {
+			n += v.Visits
+		}
+	}
+	return n
+}
+
+// rawAnalytics is the response format, keyed by acronym, year and month.
+type rawAnalytics map[string]map[string]map[string]int
+
+// parse converts the response into analytics sorted by acronym. Keys that
+// are not numbers are ignored.
+func (r rawAnalytics) parse() []*OntologyAnalytics {
+	res := make([]*OntologyAnalytics, 0, len(r))
+
+	for acr, years := range r {
+		a := &OntologyAnalytics{Ontology: acr}
+
+		for ys, months := range years {
+			y, err := strconv.Atoi(ys)
+			if err != nil {
+				continue
+			}
+
+			for ms, n := range months {
+				m, err := strconv.Atoi(ms)
+				if err != nil {
+					continue
+				}
+
+				a.Visits = append(a.Visits, MonthlyVisits{
+					Year:   y,
+					Month:  time.Month(m),
+					Visits: n,
+				})
+			}
+		}
+
+		sort.Slice(a.Visits, func(i, j int) bool {
+			vi, vj := a.Visits[i], a.Visits[j]
+			if vi.Year != vj.Year {
+				return vi.Year < vj.Year
+			}
+			return vi.Month < vj.Month
+		})
+
+		res = append(res, a)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Ontology < res[j].Ontology
+	})
+
+	return res
+}
@@ -0,0 +1,64 @@
+package bioportal
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-querystring/query"
+)
+
+func TestAnalyticsOptions(t *testing.T) {
+	v, err := query.Values(AnalyticsOptions{
+		Year:       2017,
+		Month:      int(time.March),
+		Ontologies: []string{"NCIT", "HP"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := v.Encode(); s != "month=3&ontologies=NCIT%2CHP&year=2017" {
+		t.Errorf("unexpected query %s", s)
+	}
+
+	if v, _ = query.Values(AnalyticsOptions{}); len(v) != 0 {
+		t.Errorf("expected an empty query, got %s", v.Encode())
+	}
+}
+
+func TestRawAnalyticsParse(t *testing.T) {
+	raw := rawAnalytics{
+		"NCIT": {
+			"2017": {"3": 10, "1": 5},
+			"2016": {"12": 2, "total": 100},
+		},
+		"HP": {
+			"2017": {"2": 1},
+		},
+	}
+
+	res := raw.parse()
+
+	if len(res) != 2 || res[0].Ontology != "HP" || res[1].Ontology != "NCIT" {
+		t.Fatalf("unexpected analytics %+v", res)
+	}
+
+	exp := []MonthlyVisits{
+		{2016, time.December, 2},
+		{2017, time.January, 5},
+		{2017, time.March, 10},
+	}
+
+	if a := res[1]; !reflect.DeepEqual(a.Visits, exp) {
+		t.Errorf("expected %v, got %v", exp, a.Visits)
+	}
+
+	if n := res[1].Total(); n != 17 {
+		t.Errorf("expected 17 visits, got %d", n)
+	}
+
+	if n := res[1].Since(2017, time.February); n != 10 {
+		t.Errorf("expected 10 visits, got %d", n)
+	}
+}
@@ -0,0 +1,48 @@
+package bioportal
+
+import "context"
+
+type User struct {
+	Username  string   `json:"username"`
+	Email     string   `json:"email"`
+	FirstName string   `json:"firstName"`
+	LastName  string   `json:"lastName"`
+	Role      []string `json:"role"`
+	APIKey    string   `json:"apikey"`
+	ID        string   `json:"@id"`
+	Type      string   `json:"@type"`
+}
+
+// Credentials provides the API key used for a request. Implementations can
+// inspect the request context to select a key per user or tenant.
+type Credentials interface {
+	APIKey(ctx context.Context) (string, error)
+}
+
+// CredentialsFunc adapts a function to the Credentials interface.
+type CredentialsFunc func(ctx context.Context) (string, error)
+
+func (f CredentialsFunc) APIKey(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// StaticKey is a fixed API key.
+type StaticKey string
+
+func (k StaticKey) APIKey(ctx context.Context) (string, error) {
+	return string(k), nil
+}
+
+type apiKeyContextKey struct{}
+
+// ContextWithAPIKey returns a context that overrides the API key of requests
+// made with it. See Client.WithContext.
+func ContextWithAPIKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+// APIKeyFromContext returns the API key set by ContextWithAPIKey.
+func APIKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(apiKeyContextKey{}).(string)
+	return key, ok
+}
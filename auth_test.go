@@ -0,0 +1,48 @@
+package bioportal
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCredentials(t *testing.T) {
+	var got string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	defer func(u string) { BaseURL = u }(BaseURL)
+	BaseURL = srv.URL
+
+	c := NewClient("default")
+
+	if _, err := c.Groups(); err != nil {
+		t.Fatal(err)
+	}
+	if got != "apikey token=default" {
+		t.Errorf("expected default key, got %q", got)
+	}
+
+	c.Credentials = StaticKey("provider")
+
+	if _, err := c.Groups(); err != nil {
+		t.Fatal(err)
+	}
+	if got != "apikey token=provider" {
+		t.Errorf("expected provider key, got %q", got)
+	}
+
+	ctx := ContextWithAPIKey(context.Background(), "user")
+
+	if _, err := c.WithContext(ctx).Groups(); err != nil {
+		t.Fatal(err)
+	}
+	if got != "apikey token=user" {
+		t.Errorf("expected context key, got %q", got)
+	}
+}
@@ -6,6 +6,7 @@ type Ontology struct {
 	Name           string   `json:"name"`
 	SummaryOnly    bool     `json:"summaryOnly"`
 	OntologyType   string   `json:"ontologyType"`
+	ViewOf         string   `json:"viewOf"`
 	ID             string   `json:"@id"`
 	Type           string   `json:"@type"`
 	Links          struct {
@@ -116,7 +117,7 @@ type ClassesPaginated struct {
 		NextPage interface{} `json:"nextPage"`
 		PrevPage interface{} `json:"prevPage"`
 	} `json:"links"`
-	Collection Class `json:"collection"`
+	Collection []Class `json:"collection"`
 }
 
 type Mapping struct {
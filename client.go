@@ -1,6 +1,8 @@
 package bioportal
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -52,9 +54,76 @@ func DefaultBaseOptions() *BaseOptions {
 type Client struct {
 	APIKey string
 	HTTP   *http.Client
+
+	// Credentials, if set, provides the API key for each request in place
+	// of APIKey. A key set on the request context takes precedence over both.
+	Credentials Credentials
+
+	// Middleware wraps the HTTP transport, the first being the outermost.
+	Middleware []Middleware
+
+	ctx context.Context
+}
+
+// Use appends middleware to the client.
+func (c *Client) Use(mw ...Middleware) {
+	c.Middleware = append(c.Middleware, mw...)
+}
+
+// httpClient returns the HTTP client with the middleware applied to its
+// transport.
+func (c *Client) httpClient() *http.Client {
+	if len(c.Middleware) == 0 {
+		return c.HTTP
+	}
+
+	hc := *c.HTTP
+
+	rt := hc.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	for i := len(c.Middleware) - 1; i >= 0; i-- {
+		rt = c.Middleware[i](rt)
+	}
+
+	hc.Transport = rt
+	return &hc
+}
+
+// WithContext returns a shallow copy of the client whose requests are bound
+// to ctx. Cancelling ctx aborts any in-flight request made through the copy.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	if ctx == nil {
+		panic("nil context")
+	}
+
+	c2 := *c
+	c2.ctx = ctx
+	return &c2
 }
 
-func (c *Client) request(path string) (*http.Request, error) {
+func (c *Client) context() context.Context {
+	if c.ctx != nil {
+		return c.ctx
+	}
+	return context.Background()
+}
+
+func (c *Client) apiKey(ctx context.Context) (string, error) {
+	if key, ok := APIKeyFromContext(ctx); ok {
+		return key, nil
+	}
+
+	if c.Credentials != nil {
+		return c.Credentials.APIKey(ctx)
+	}
+
+	return c.APIKey, nil
+}
+
+func (c *Client) request(method, path string) (*http.Request, error) {
 	var u string
 
 	if strings.HasPrefix(path, "/") {
@@ -68,19 +137,31 @@ func (c *Client) request(path string) (*http.Request, error) {
 		u = path
 	}
 
-	req, err := http.NewRequest("GET", u, nil)
+	req, err := http.NewRequest(method, u, nil)
 	if err != nil {
 		return nil, err
 	}
+	req = req.WithContext(c.context())
 
-	req.Header.Set("Authorization", fmt.Sprintf("apikey token=%s", c.APIKey))
+	key, err := c.apiKey(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("apikey token=%s", key))
 	req.Header.Set("Accept", "application/json")
 
 	return req, nil
 }
 
 func (c *Client) Send(path string, params interface{}) (io.ReadCloser, error) {
-	req, err := c.request(path)
+	return c.Do("GET", path, params, nil)
+}
+
+// Do sends a request using the given method. Params are encoded in the query
+// string and a non-nil body is encoded as JSON.
+func (c *Client) Do(method, path string, params, body interface{}) (io.ReadCloser, error) {
+	req, err := c.request(method, path)
 	if err != nil {
 		return nil, err
 	}
@@ -94,29 +175,58 @@ func (c *Client) Send(path string, params interface{}) (io.ReadCloser, error) {
 		req.URL.RawQuery = v.Encode()
 	}
 
+	var payload []byte
+
+	if body != nil {
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+		req.ContentLength = int64(len(payload))
+	}
+
 	var resp *http.Response
 
+	hc := c.httpClient()
+
 	for {
 	MAKE_REQ:
-		resp, err = c.HTTP.Do(req)
+		if payload != nil {
+			req.Body = ioutil.NopCloser(bytes.NewReader(payload))
+		}
+
+		resp, err = hc.Do(req)
 		if err != nil {
 			return nil, err
 		}
 
 		switch resp.StatusCode {
 		case http.StatusTooManyRequests:
-			time.Sleep(100 * time.Millisecond)
+			resp.Body.Close()
+			select {
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			case <-time.After(100 * time.Millisecond):
+			}
 			goto MAKE_REQ
 
 		case http.StatusRequestURITooLong:
+			resp.Body.Close()
 			return nil, fmt.Errorf("request URI too long:\n%s", path)
 		}
 
-		if resp.StatusCode != 200 {
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 			b, _ := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			// Proxies may respond with an HTML page rather than JSON.
 			var apiErr APIError
-			if err := json.Unmarshal(b, &apiErr); err != nil {
-				panic(fmt.Sprintf("[%s] %s:\n%s", resp.Status, err, string(b)))
+			if err := json.Unmarshal(b, &apiErr); err != nil || apiErr.Status == 0 {
+				apiErr.Status = resp.StatusCode
+			}
+			if len(apiErr.Errors) == 0 {
+				apiErr.Errors = []string{strings.TrimSpace(string(b))}
 			}
 			return nil, &apiErr
 		}
@@ -129,8 +239,8 @@ func (c *Client) Send(path string, params interface{}) (io.ReadCloser, error) {
 }
 
 func (c *Client) search(opts *SearchOptions) (io.ReadCloser, error) {
-	if opts.Query == "" {
-		return nil, errors.New("query cannot be empty")
+	if err := opts.Validate(); err != nil {
+		return nil, err
 	}
 
 	return c.Send("/search", opts)
@@ -162,11 +272,11 @@ func (c *Client) Search(opts SearchOptions) (*SearchResult, error) {
 }
 
 func (c *Client) recommend(opts *RecommendOptions) (io.ReadCloser, error) {
-	if len(opts.Terms) == 0 {
-		return nil, errors.New("at least one term is required")
+	if err := opts.Validate(); err != nil {
+		return nil, err
 	}
 
-	return c.Send("/recommender", opts)
+	return c.Send("/recommender", opts.params())
 }
 
 func (c *Client) RecommendRead(w io.Writer, opts RecommendOptions) (int64, error) {
@@ -243,6 +353,482 @@ func (c *Client) Class(ontology, class string) (*Class, error) {
 	return &cl, nil
 }
 
+func (c *Client) Classes(ontology string, opts BaseOptions) (*ClassesPaginated, error) {
+	if ontology == "" {
+		return nil, errors.New("ontology cannot be empty")
+	}
+
+	var res ClassesPaginated
+	if err := c.get(fmt.Sprintf("/ontologies/%s/classes", ontology), &opts, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// Views returns the views defined on an ontology. Views are addressed by
+// their own acronym, which can be passed to the View* methods.
+func (c *Client) Views(ontology string) ([]*Ontology, error) {
+	if ontology == "" {
+		return nil, errors.New("ontology cannot be empty")
+	}
+
+	var res []*Ontology
+	if err := c.get(fmt.Sprintf("/ontologies/%s/views", ontology), nil, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *Client) ViewClass(view, class string) (*Class, error) {
+	return c.Class(view, class)
+}
+
+func (c *Client) ViewClasses(view string, opts BaseOptions) (*ClassesPaginated, error) {
+	return c.Classes(view, opts)
+}
+
+// SearchView restricts a search to a single view.
+func (c *Client) SearchView(view string, opts SearchOptions) (*SearchResult, error) {
+	if view == "" {
+		return nil, errors.New("view cannot be empty")
+	}
+
+	opts.Ontologies = []string{view}
+	opts.AlsoSearchViews = true
+
+	return c.Search(opts)
+}
+
+func (c *Client) Instances(ontology string, opts BaseOptions) (*InstancesPaginated, error) {
+	if ontology == "" {
+		return nil, errors.New("ontology cannot be empty")
+	}
+
+	var res InstancesPaginated
+	if err := c.get(fmt.Sprintf("/ontologies/%s/instances", ontology), &opts, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (c *Client) ClassInstances(ontology, class string, opts BaseOptions) (*InstancesPaginated, error) {
+	if ontology == "" {
+		return nil, errors.New("ontology cannot be empty")
+	}
+
+	if class == "" {
+		return nil, errors.New("class cannot be empty")
+	}
+
+	path := fmt.Sprintf("/ontologies/%s/classes/%s/instances", ontology, url.QueryEscape(class))
+
+	var res InstancesPaginated
+	if err := c.get(path, &opts, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// EachInstance pages through all instances of an ontology, or of a class if
+// one is given, calling fn for each. Iteration stops at the first error.
+func (c *Client) EachInstance(ontology, class string, opts BaseOptions, fn func(*Instance) error) error {
+	if opts.Page == 0 {
+		opts.Page = 1
+	}
+
+	for {
+		var (
+			res *InstancesPaginated
+			err error
+		)
+
+		if class == "" {
+			res, err = c.Instances(ontology, opts)
+		} else {
+			res, err = c.ClassInstances(ontology, class, opts)
+		}
+		if err != nil {
+			return err
+		}
+
+		for i := range res.Collection {
+			if err := fn(&res.Collection[i]); err != nil {
+				return err
+			}
+		}
+
+		if res.NextPage == nil || res.Page >= res.PageCount {
+			return nil
+		}
+
+		opts.Page = res.Page + 1
+	}
+}
+
+func (c *Client) ProvisionalClasses(opts BaseOptions) ([]*ProvisionalClass, error) {
+	var res []*ProvisionalClass
+	if err := c.get("/provisional_classes", &opts, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *Client) ProvisionalClass(id string) (*ProvisionalClass, error) {
+	if id == "" {
+		return nil, errors.New("id cannot be empty")
+	}
+
+	var res ProvisionalClass
+	if err := c.get(provisionalPath(id), nil, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (c *Client) CreateProvisionalClass(pc *ProvisionalClass) (*ProvisionalClass, error) {
+	if pc.Label == "" {
+		return nil, errors.New("label cannot be empty")
+	}
+
+	if pc.Creator == "" {
+		return nil, errors.New("creator cannot be empty")
+	}
+
+	rc, err := c.Do("POST", "/provisional_classes", nil, pc)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var res ProvisionalClass
+	if err := json.NewDecoder(rc).Decode(&res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+// UpdateProvisionalClass applies the non-empty fields of pc to the
+// provisional class identified by id.
+func (c *Client) UpdateProvisionalClass(id string, pc *ProvisionalClass) error {
+	if id == "" {
+		return errors.New("id cannot be empty")
+	}
+
+	patch := *pc
+	patch.ID = ""
+	patch.Type = ""
+
+	rc, err := c.Do("PATCH", provisionalPath(id), nil, &patch)
+	if err != nil {
+		return err
+	}
+
+	return rc.Close()
+}
+
+func (c *Client) DeleteProvisionalClass(id string) error {
+	if id == "" {
+		return errors.New("id cannot be empty")
+	}
+
+	rc, err := c.Do("DELETE", provisionalPath(id), nil, nil)
+	if err != nil {
+		return err
+	}
+
+	return rc.Close()
+}
+
+func (c *Client) get(path string, params interface{}, v interface{}) error {
+	rc, err := c.Send(path, params)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	return json.NewDecoder(rc).Decode(v)
+}
+
+func (c *Client) Ontologies(opts BaseOptions) ([]*Ontology, error) {
+	var res []*Ontology
+	if err := c.get("/ontologies", &opts, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *Client) Ontology(ontology string) (*Ontology, error) {
+	if ontology == "" {
+		return nil, errors.New("ontology cannot be empty")
+	}
+
+	var res Ontology
+	if err := c.get(fmt.Sprintf("/ontologies/%s", ontology), nil, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (c *Client) LatestSubmission(ontology string) (*Submission, error) {
+	if ontology == "" {
+		return nil, errors.New("ontology cannot be empty")
+	}
+
+	opts := BaseOptions{Include: "submissionId,version,released,creationDate,status,submissionStatus,hasOntologyLanguage,description"}
+
+	var res Submission
+	if err := c.get(fmt.Sprintf("/ontologies/%s/latest_submission", ontology), &opts, &res); err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (c *Client) Groups() ([]*Group, error) {
+	var res []*Group
+	if err := c.get("/groups", nil, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *Client) GroupOntologies(group string) ([]*Ontology, error) {
+	if group == "" {
+		return nil, errors.New("group cannot be empty")
+	}
+
+	var res []*Ontology
+	if err := c.get(fmt.Sprintf("/groups/%s/ontologies", group), nil, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *Client) Categories() ([]*Category, error) {
+	var res []*Category
+	if err := c.get("/categories", nil, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *Client) CategoryOntologies(category string) ([]*Ontology, error) {
+	if category == "" {
+		return nil, errors.New("category cannot be empty")
+	}
+
+	var res []*Ontology
+	if err := c.get(fmt.Sprintf("/categories/%s/ontologies", category), nil, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// FilterOntologies returns the ontologies in the catalog that are members of
+// at least one of the filter's groups and at least one of its categories.
+func (c *Client) FilterOntologies(opts BaseOptions, f OntologyFilter) ([]*Ontology, error) {
+	onts, err := c.Ontologies(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := c.acronymSet(f.Groups, c.GroupOntologies)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := c.acronymSet(f.Categories, c.CategoryOntologies)
+	if err != nil {
+		return nil, err
+	}
+
+	return filterOntologies(onts, groups, categories), nil
+}
+
+// acronymSet collects the acronyms of the ontologies returned by fetch for
+// each key. A nil set is returned if no keys are given.
+func (c *Client) acronymSet(keys []string, fetch func(string) ([]*Ontology, error)) (map[string]struct{}, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	set := make(map[string]struct{})
+
+	for _, k := range keys {
+		onts, err := fetch(k)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range onts {
+			set[o.Acronym] = struct{}{}
+		}
+	}
+
+	return set, nil
+}
+
+// ClassTree returns the ontology's roots with the hierarchy expanded along
+// the paths to the class.
+func (c *Client) ClassTree(ontology, class string) ([]Tree, error) {
+	if ontology == "" {
+		return nil, errors.New("ontology cannot be empty")
+	}
+
+	if class == "" {
+		return nil, errors.New("class cannot be empty")
+	}
+
+	path := fmt.Sprintf("/ontologies/%s/classes/%s/tree", ontology, url.QueryEscape(class))
+
+	var res []Tree
+	if err := c.get(path, nil, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (c *Client) Mappings(ontology, class string) ([]*Mapping, error) {
+	if ontology == "" {
+		return nil, errors.New("ontology cannot be empty")
+	}
+
+	if class == "" {
+		return nil, errors.New("class cannot be empty")
+	}
+
+	path := fmt.Sprintf("/ontologies/%s/classes/%s/mappings", ontology, url.QueryEscape(class))
+
+	var res []*Mapping
+	if err := c.get(path, nil, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+type DownloadOptions struct {
+	// Format is the export format, such as "csv". The ontology's original
+	// source file is downloaded if empty.
+	Format string `url:"download_format,omitempty"`
+}
+
+// Download writes the latest submission of an ontology to w.
+func (c *Client) Download(w io.Writer, ontology string, opts DownloadOptions) (int64, error) {
+	if ontology == "" {
+		return 0, errors.New("ontology cannot be empty")
+	}
+
+	rc, err := c.withoutTimeout().Send(fmt.Sprintf("/ontologies/%s/download", ontology), &opts)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	return io.Copy(w, rc)
+}
+
+// withoutTimeout returns a copy of the client whose HTTP client has no
+// total timeout. The timeout covers reading the response body, which would
+// abort large downloads. A context can still be used to bound them.
+func (c *Client) withoutTimeout() *Client {
+	if c.HTTP == nil || c.HTTP.Timeout == 0 {
+		return c
+	}
+
+	hc := *c.HTTP
+	hc.Timeout = 0
+
+	cc := *c
+	cc.HTTP = &hc
+	return &cc
+}
+
+// Authenticate exchanges a user's credentials for their account, including
+// their personal API key.
+func (c *Client) Authenticate(username, password string) (*User, error) {
+	if username == "" || password == "" {
+		return nil, errors.New("username and password are required")
+	}
+
+	body := map[string]string{
+		"user":     username,
+		"password": password,
+	}
+
+	rc, err := c.Do("POST", "/users/authenticate", nil, body)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var u User
+	if err := json.NewDecoder(rc).Decode(&u); err != nil {
+		return nil, err
+	}
+
+	return &u, nil
+}
+
+// Analytics returns the monthly visits of ontologies. The API only counts
+// visits per ontology, so there is no usage data for individual classes.
+func (c *Client) Analytics(opts AnalyticsOptions) ([]*OntologyAnalytics, error) {
+	var raw rawAnalytics
+	if err := c.get("/analytics", &opts, &raw); err != nil {
+		return nil, err
+	}
+
+	return raw.parse(), nil
+}
+
+// OntologyAnalytics returns the monthly visits of a single ontology.
+func (c *Client) OntologyAnalytics(ontology string) (*OntologyAnalytics, error) {
+	if ontology == "" {
+		return nil, errors.New("ontology cannot be empty")
+	}
+
+	var raw rawAnalytics
+	if err := c.get(fmt.Sprintf("/ontologies/%s/analytics", ontology), nil, &raw); err != nil {
+		return nil, err
+	}
+
+	for _, a := range raw.parse() {
+		if a.Ontology == ontology {
+			return a, nil
+		}
+	}
+
+	return &OntologyAnalytics{Ontology: ontology}, nil
+}
+
+// DownloadSubmission writes a specific submission of an ontology to w.
+func (c *Client) DownloadSubmission(w io.Writer, ontology string, submission int, opts DownloadOptions) (int64, error) {
+	if ontology == "" {
+		return 0, errors.New("ontology cannot be empty")
+	}
+
+	rc, err := c.withoutTimeout().Send(fmt.Sprintf("/ontologies/%s/submissions/%d/download", ontology, submission), &opts)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	return io.Copy(w, rc)
+}
+
 func NewClient(apiKey string) *Client {
 	return &Client{
 		APIKey: apiKey,
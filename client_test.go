@@ -12,7 +12,10 @@ func TestSearch(t *testing.T) {
 	}
 
 	c := NewClient(apiKey)
-	res, err := c.Search("audiology", nil)
+	opts := DefaultSearchOptions()
+	opts.Query = "audiology"
+
+	res, err := c.Search(*opts)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	neo "github.com/johnnadratowski/golang-neo4j-bolt-driver"
+)
+
+// DefaultBatchSize is the number of rows sent per UNWIND statement.
+var DefaultBatchSize = 1000
+
+// Indexes used by vocab-service lookups and by the MERGE statements below.
+var boltIndexes = []string{
+	`CREATE INDEX ON :Vocabulary(id)`,
+	`CREATE INDEX ON :Class(id)`,
+	`CREATE INDEX ON :Class(code)`,
+}
+
+const (
+	boltMergeVocab = `
+		MERGE (v:Vocabulary {id: {id}})
+		SET v.label = {label}
+		SET v += {submission}
+	`
+
+	boltMergeClasses = `
+		MATCH (v:Vocabulary {id: {vocab}})
+		UNWIND {rows} AS row
+		MERGE (c:Class {id: row.id})
+		SET c.label = row.label,
+			c.code = row.code,
+			c.synonyms = row.synonyms,
+			c.definitions = row.definitions,
+			c.obsolete = row.obsolete,
+			c.cui = row.cui,
+			c.semanticTypes = row.semanticTypes
+		SET c += row.props
+		MERGE (c)-[:classOf]->(v)
+	`
+
+	boltMergeSubClassOf = `
+		UNWIND {rows} AS row
+		MATCH (c:Class {id: row.child}), (p:Class {id: row.parent})
+		MERGE (c)-[:subClassOf]->(p)
+	`
+
+	// The relationship type cannot be a parameter.
+	boltMergeRelation = `
+		UNWIND {rows} AS row
+		MATCH (c:Class {id: row.source}), (t:Class {id: row.target})
+		MERGE (c)-[:%s]->(t)
+	`
+
+	boltMarkObsolete = `
+		UNWIND {rows} AS row
+		MATCH (c:Class {id: row.id})
+		SET c.obsolete = true
+	`
+
+	boltRelabel = `
+		UNWIND {rows} AS row
+		MATCH (c:Class {id: row.id})
+		SET c.label = row.label
+	`
+
+	boltDeleteSubClassOf = `
+		UNWIND {rows} AS row
+		MATCH (c:Class {id: row.id})-[r:subClassOf]->()
+		DELETE r
+	`
+)
+
+// boltWriter loads a vocabulary into a running Neo4j instance. Classes are
+// merged in batches as they are written; subclass edges and relations are
+// held until Close so that every target exists when they are created.
+type boltWriter struct {
+	conn    neo.Conn
+	vocabID string
+	props   []propMapping
+	size    int
+
+	classes   []interface{}
+	edges     []interface{}
+	relations map[string][]interface{}
+}
+
+func newBoltWriter(url string, v *vocab, size int) (*boltWriter, error) {
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+
+	if !strings.HasPrefix(url, "bolt://") {
+		url = "bolt://" + url
+	}
+
+	conn, err := neo.NewDriver().OpenNeo(url)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, q := range boltIndexes {
+		if _, err := conn.ExecNeo(q, nil); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	sub := make(map[string]interface{})
+	if s := v.Submission; s != nil {
+		sub["submissionId"] = int64(s.ID)
+		sub["version"] = s.Version
+		sub["released"] = s.Released
+	}
+
+	_, err = conn.ExecNeo(boltMergeVocab, map[string]interface{}{
+		"id":         v.ID,
+		"label":      v.Label,
+		"submission": sub,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &boltWriter{
+		conn:      conn,
+		vocabID:   v.ID,
+		props:     v.Props,
+		size:      size,
+		relations: make(map[string][]interface{}),
+	}, nil
+}
+
+func list(a []string) []interface{} {
+	l := make([]interface{}, len(a))
+	for i, s := range a {
+		l[i] = s
+	}
+	return l
+}
+
+// propValue converts a mapped column value based on the neo4j-import type
+// in the property spec. Array types are split on the array delimiter.
+func propValue(spec, v string) (string, interface{}) {
+	name, typ := spec, ""
+	if i := strings.Index(spec, ":"); i >= 0 {
+		name, typ = spec[:i], spec[i+1:]
+	}
+
+	switch {
+	case strings.HasSuffix(typ, "[]"):
+		return name, list(split(v))
+	case typ == "boolean":
+		return name, v == "true"
+	default:
+		return name, v
+	}
+}
+
+func (w *boltWriter) Write(c *class) error {
+	props := make(map[string]interface{}, len(c.Props))
+	for i, v := range c.Props {
+		k, v := propValue(w.props[i].Property, v)
+		props[k] = v
+	}
+
+	w.classes = append(w.classes, map[string]interface{}{
+		"id":            c.ID,
+		"label":         c.Label,
+		"code":          c.Code,
+		"synonyms":      list(c.Synonyms),
+		"definitions":   list(c.Definitions),
+		"obsolete":      c.Obsolete == "true",
+		"cui":           list(c.CUI),
+		"semanticTypes": list(c.SemanticTypes),
+		"props":         props,
+	})
+
+	for _, pid := range c.Parents {
+		w.edges = append(w.edges, map[string]interface{}{
+			"child":  c.ID,
+			"parent": pid,
+		})
+	}
+
+	for _, r := range c.Relations {
+		w.relations[r.Type] = append(w.relations[r.Type], map[string]interface{}{
+			"source": c.ID,
+			"target": r.Target,
+		})
+	}
+
+	if len(w.classes) >= w.size {
+		return w.flushClasses()
+	}
+
+	return nil
+}
+
+func (w *boltWriter) flushClasses() error {
+	if len(w.classes) == 0 {
+		return nil
+	}
+
+	_, err := w.conn.ExecNeo(boltMergeClasses, map[string]interface{}{
+		"vocab": w.vocabID,
+		"rows":  w.classes,
+	})
+
+	w.classes = w.classes[:0]
+	return err
+}
+
+func (w *boltWriter) flushEdges() error {
+	err := w.exec(boltMergeSubClassOf, w.edges)
+	w.edges = nil
+	if err != nil {
+		return err
+	}
+
+	for typ, rows := range w.relations {
+		q := fmt.Sprintf(boltMergeRelation, quoteName(typ))
+		if err := w.exec(q, rows); err != nil {
+			return err
+		}
+		delete(w.relations, typ)
+	}
+
+	return nil
+}
+
+// quoteName quotes a relationship type for use in a Cypher statement.
+func quoteName(s string) string {
+	return "`" + strings.Replace(s, "`", "``", -1) + "`"
+}
+
+// exec runs the query with the rows in batches.
+func (w *boltWriter) exec(query string, rows []interface{}) error {
+	for len(rows) > 0 {
+		n := w.size
+		if n > len(rows) {
+			n = len(rows)
+		}
+
+		_, err := w.conn.ExecNeo(query, map[string]interface{}{
+			"rows": rows[:n],
+		})
+		if err != nil {
+			return err
+		}
+
+		rows = rows[n:]
+	}
+
+	return nil
+}
+
+// Apply updates the loaded vocabulary with the changes from a new release.
+// Removed classes are marked obsolete and reparented classes have their
+// subclass edges replaced. Close must be called to create the new edges.
+func (w *boltWriter) Apply(d *vocabDiff) error {
+	for _, c := range d.Added {
+		if err := w.Write(c); err != nil {
+			return err
+		}
+	}
+
+	if err := w.flushClasses(); err != nil {
+		return err
+	}
+
+	var rows []interface{}
+	for _, c := range d.Removed {
+		rows = append(rows, map[string]interface{}{"id": c.ID})
+	}
+	if err := w.exec(boltMarkObsolete, rows); err != nil {
+		return err
+	}
+
+	rows = nil
+	for _, r := range d.Relabeled {
+		rows = append(rows, map[string]interface{}{"id": r.Class.ID, "label": r.Class.Label})
+	}
+	if err := w.exec(boltRelabel, rows); err != nil {
+		return err
+	}
+
+	rows = nil
+	for _, r := range d.Reparented {
+		rows = append(rows, map[string]interface{}{"id": r.Class.ID})
+		for _, pid := range r.Class.Parents {
+			w.edges = append(w.edges, map[string]interface{}{
+				"child":  r.Class.ID,
+				"parent": pid,
+			})
+		}
+	}
+
+	return w.exec(boltDeleteSubClassOf, rows)
+}
+
+func (w *boltWriter) Close() error {
+	err := w.flushClasses()
+	if err == nil {
+		err = w.flushEdges()
+	}
+
+	if cerr := w.conn.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"io"
+	"log"
+	"strings"
+)
+
+// class is a vocabulary class read from an input file.
+type class struct {
+	ID            string
+	Label         string
+	Code          string
+	Synonyms      []string
+	Definitions   []string
+	Obsolete      string
+	CUI           []string
+	SemanticTypes []string
+	Parents       []string
+
+	// Relations are typed relationships to other classes, such as part_of.
+	Relations []relation
+
+	// Props are the values of additional properties, in the order of the
+	// property mappings.
+	Props []string
+}
+
+// relation is a typed relationship from a class to another class.
+type relation struct {
+	Type   string
+	Target string
+}
+
+// codeFromID derives the class code from the last segment of its ID.
+func codeFromID(id string) string {
+	toks := strings.Split(id, "/")
+	return toks[len(toks)-1]
+}
+
+// classReader returns classes one at a time until io.EOF.
+type classReader interface {
+	Read() (*class, error)
+}
+
+// sliceReader reads classes that are already in memory.
+type sliceReader struct {
+	classes []*class
+}
+
+func (r *sliceReader) Read() (*class, error) {
+	if len(r.classes) == 0 {
+		return nil, io.EOF
+	}
+
+	c := r.classes[0]
+	r.classes = r.classes[1:]
+	return c, nil
+}
+
+func readAll(r classReader) ([]*class, error) {
+	var classes []*class
+
+	for {
+		c, err := r.Read()
+		if err == io.EOF {
+			return classes, nil
+		} else if err != nil {
+			return nil, err
+		}
+		classes = append(classes, c)
+	}
+}
+
+// progressReader logs the number of classes read every n classes.
+type progressReader struct {
+	classReader
+	name  string
+	every int
+	rows  int
+	done  bool
+}
+
+func newProgressReader(r classReader, name string, every int) classReader {
+	if every <= 0 {
+		return r
+	}
+
+	return &progressReader{
+		classReader: r,
+		name:        name,
+		every:       every,
+	}
+}
+
+func (r *progressReader) Read() (*class, error) {
+	c, err := r.classReader.Read()
+
+	if err == io.EOF && !r.done {
+		r.done = true
+		log.Printf("%s: read %d rows", r.name, r.rows)
+	} else if err == nil {
+		r.rows++
+		if r.rows%r.every == 0 {
+			log.Printf("%s: %d rows", r.name, r.rows)
+		}
+	}
+
+	return c, err
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Standard BioPortal CSV column names.
+const (
+	colClassID       = "Class ID"
+	colLabel         = "Preferred Label"
+	colSynonyms      = "Synonyms"
+	colDefinitions   = "Definitions"
+	colObsolete      = "Obsolete"
+	colCUI           = "CUI"
+	colSemanticTypes = "Semantic Types"
+	colParents       = "Parents"
+)
+
+// propMapping maps a CSV column onto a class node property. The property
+// may include a neo4j-import type suffix such as ":string[]".
+type propMapping struct {
+	Column   string `json:"column"`
+	Property string `json:"property"`
+}
+
+// propFlag collects repeated -prop flags of the form <column>=<property>.
+type propFlag []propMapping
+
+func (p *propFlag) String() string {
+	var s []string
+	for _, m := range *p {
+		s = append(s, m.Column+"="+m.Property)
+	}
+	return strings.Join(s, ",")
+}
+
+func (p *propFlag) Set(v string) error {
+	toks := strings.SplitN(v, "=", 2)
+	if len(toks) != 2 || toks[0] == "" || toks[1] == "" {
+		return fmt.Errorf("expected <column>=<property>, got %q", v)
+	}
+
+	*p = append(*p, propMapping{
+		Column:   strings.TrimSpace(toks[0]),
+		Property: strings.TrimSpace(toks[1]),
+	})
+
+	return nil
+}
+
+// columns holds the index of each known column in a row, or -1 if the
+// column is not present.
+type columns struct {
+	id            int
+	label         int
+	synonyms      int
+	definitions   int
+	obsolete      int
+	cui           int
+	semanticTypes int
+	parents       int
+
+	// props are the indexes of the columns mapped by -prop flags.
+	props []int
+}
+
+func normColumn(s string) string {
+	return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(s, "\ufeff")))
+}
+
+// resolveColumns finds the standard and mapped columns by name in the
+// header. Matching ignores case and surrounding whitespace.
+func resolveColumns(header []string, props []propMapping) (*columns, error) {
+	idx := make(map[string]int, len(header))
+	for i, h := range header {
+		h = normColumn(h)
+		if _, ok := idx[h]; !ok {
+			idx[h] = i
+		}
+	}
+
+	find := func(name string) int {
+		if i, ok := idx[normColumn(name)]; ok {
+			return i
+		}
+		return -1
+	}
+
+	c := &columns{
+		id:            find(colClassID),
+		label:         find(colLabel),
+		synonyms:      find(colSynonyms),
+		definitions:   find(colDefinitions),
+		obsolete:      find(colObsolete),
+		cui:           find(colCUI),
+		semanticTypes: find(colSemanticTypes),
+		parents:       find(colParents),
+	}
+
+	if c.id < 0 {
+		return nil, fmt.Errorf("missing %q column", colClassID)
+	}
+
+	for _, p := range props {
+		i := find(p.Column)
+		if i < 0 {
+			return nil, fmt.Errorf("missing %q column for property %s", p.Column, p.Property)
+		}
+		c.props = append(c.props, i)
+	}
+
+	return c, nil
+}
+
+// get returns the value at index i of the row or an empty string if the
+// column is absent or the row is short.
+func get(row []string, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	return row[i]
+}
+
+// split splits a multi-valued cell on the array delimiter.
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "|")
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"log"
+)
+
+// csvReader reads classes from a BioPortal CSV export. Columns are resolved
+// by the names in the header row.
+type csvReader struct {
+	cr   *csv.Reader
+	cols *columns
+}
+
+func newCSVReader(r io.Reader, props []propMapping) (*csvReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	cols, err := resolveColumns(header, props)
+	if err != nil {
+		return nil, err
+	}
+
+	return &csvReader{
+		cr:   cr,
+		cols: cols,
+	}, nil
+}
+
+// Read returns the next class or io.EOF. Rows without a class ID are
+// skipped.
+func (r *csvReader) Read() (*class, error) {
+	for {
+		row, err := r.cr.Read()
+		if err != nil {
+			return nil, err
+		}
+
+		id := get(row, r.cols.id)
+		if id == "" {
+			log.Printf("skipping: %v", row)
+			continue
+		}
+
+		c := &class{
+			ID:            id,
+			Label:         get(row, r.cols.label),
+			Code:          codeFromID(id),
+			Synonyms:      split(get(row, r.cols.synonyms)),
+			Definitions:   split(get(row, r.cols.definitions)),
+			Obsolete:      get(row, r.cols.obsolete),
+			CUI:           split(get(row, r.cols.cui)),
+			SemanticTypes: split(get(row, r.cols.semanticTypes)),
+			Parents:       split(get(row, r.cols.parents)),
+		}
+
+		for _, i := range r.cols.props {
+			c.Props = append(c.Props, get(row, i))
+		}
+
+		return c, nil
+	}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// relabel is a class whose preferred label changed.
+type relabel struct {
+	Class    *class
+	OldLabel string
+}
+
+// reparent is a class whose set of parents changed.
+type reparent struct {
+	Class      *class
+	OldParents []string
+}
+
+// vocabDiff holds the changes between two releases of a vocabulary.
+type vocabDiff struct {
+	Added      []*class
+	Removed    []*class
+	Relabeled  []*relabel
+	Reparented []*reparent
+}
+
+func (d *vocabDiff) String() string {
+	return fmt.Sprintf("%d added, %d removed, %d relabeled, %d reparented",
+		len(d.Added), len(d.Removed), len(d.Relabeled), len(d.Reparented))
+}
+
+func sameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	a = append([]string{}, a...)
+	b = append([]string{}, b...)
+	sort.Strings(a)
+	sort.Strings(b)
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// diffClasses compares the previous release of a vocabulary with the new
+// one. Added, relabeled and reparented classes are in the order of the new
+// release; removed classes are in the order of the previous one.
+func diffClasses(prev, next []*class) *vocabDiff {
+	d := &vocabDiff{}
+
+	old := make(map[string]*class, len(prev))
+	for _, c := range prev {
+		old[c.ID] = c
+	}
+
+	seen := make(map[string]struct{}, len(next))
+
+	for _, c := range next {
+		seen[c.ID] = struct{}{}
+
+		o, ok := old[c.ID]
+		if !ok {
+			d.Added = append(d.Added, c)
+			continue
+		}
+
+		if o.Label != c.Label {
+			d.Relabeled = append(d.Relabeled, &relabel{Class: c, OldLabel: o.Label})
+		}
+
+		if !sameSet(o.Parents, c.Parents) {
+			d.Reparented = append(d.Reparented, &reparent{Class: c, OldParents: o.Parents})
+		}
+	}
+
+	for _, c := range prev {
+		if _, ok := seen[c.ID]; !ok {
+			d.Removed = append(d.Removed, c)
+		}
+	}
+
+	return d
+}
+
+// writeDiffCSV writes a file per kind of change and returns a Cypher script
+// that applies them with LOAD CSV. Removed classes are marked obsolete
+// rather than deleted. Typed relations are created for added classes only.
+// The script refers to the files by name, so they must be moved into the
+// import directory of the Neo4j server.
+func writeDiffCSV(dir, vocabID string, props []propMapping, d *vocabDiff) (string, error) {
+	name := func(kind string) string {
+		return filepath.Join(dir, fmt.Sprintf("%s_diff_%s.csv", vocabID, kind))
+	}
+
+	// fileURL returns the URL of the file relative to the import directory.
+	fileURL := func(kind string) string {
+		return quoteString("file:///" + url.PathEscape(filepath.Base(name(kind))))
+	}
+
+	header := []string{"id", "label", "code", "synonyms", "definitions", "obsolete", "cui", "semanticTypes", "parents"}
+	for _, p := range props {
+		header = append(header, propColumn(p))
+	}
+
+	added, err := createCSV(name("added"), header)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range d.Added {
+		added.Write(append([]string{
+			c.ID,
+			c.Label,
+			c.Code,
+			strings.Join(c.Synonyms, "|"),
+			strings.Join(c.Definitions, "|"),
+			c.Obsolete,
+			strings.Join(c.CUI, "|"),
+			strings.Join(c.SemanticTypes, "|"),
+			strings.Join(c.Parents, "|"),
+		}, c.Props...))
+	}
+	if err := added.Close(); err != nil {
+		return "", err
+	}
+
+	removed, err := createCSV(name("removed"), []string{"id"})
+	if err != nil {
+		return "", err
+	}
+	for _, c := range d.Removed {
+		removed.Write([]string{c.ID})
+	}
+	if err := removed.Close(); err != nil {
+		return "", err
+	}
+
+	relabeled, err := createCSV(name("relabeled"), []string{"id", "oldLabel", "label"})
+	if err != nil {
+		return "", err
+	}
+	for _, r := range d.Relabeled {
+		relabeled.Write([]string{r.Class.ID, r.OldLabel, r.Class.Label})
+	}
+	if err := relabeled.Close(); err != nil {
+		return "", err
+	}
+
+	reparented, err := createCSV(name("reparented"), []string{"id", "oldParents", "parents"})
+	if err != nil {
+		return "", err
+	}
+	for _, r := range d.Reparented {
+		reparented.Write([]string{r.Class.ID, strings.Join(r.OldParents, "|"), strings.Join(r.Class.Parents, "|")})
+	}
+	if err := reparented.Close(); err != nil {
+		return "", err
+	}
+
+	// Typed relations of the added classes, created with a statement per
+	// type since the type cannot be read from the row.
+	relations, err := createCSV(name("relations"), []string{"source", "type", "target"})
+	if err != nil {
+		return "", err
+	}
+	var types []string
+	for _, c := range d.Added {
+		for _, r := range c.Relations {
+			relations.Write([]string{c.ID, r.Type, r.Target})
+			types = appendUnique(types, r.Type)
+		}
+	}
+	if err := relations.Close(); err != nil {
+		return "", err
+	}
+	sort.Strings(types)
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, `// Added classes.
+USING PERIODIC COMMIT
+LOAD CSV WITH HEADERS FROM %[2]s AS row
+MATCH (v:Vocabulary {id: %[1]s})
+MERGE (c:Class {id: row.id})
+SET c.label = row.label,
+	c.code = row.code,
+	c.synonyms = [x IN split(coalesce(row.synonyms, ''), '|') WHERE x <> ''],
+	c.definitions = [x IN split(coalesce(row.definitions, ''), '|') WHERE x <> ''],
+	c.obsolete = coalesce(row.obsolete, '') = 'true',
+	c.cui = [x IN split(coalesce(row.cui, ''), '|') WHERE x <> ''],
+	c.semanticTypes = [x IN split(coalesce(row.semanticTypes, ''), '|') WHERE x <> '']%[6]s
+MERGE (c)-[:classOf]->(v);
+
+USING PERIODIC COMMIT
+LOAD CSV WITH HEADERS FROM %[2]s AS row
+WITH row WHERE row.parents IS NOT NULL
+UNWIND split(row.parents, '|') AS pid
+MATCH (c:Class {id: row.id}), (p:Class {id: pid})
+MERGE (c)-[:subClassOf]->(p);
+
+// Removed classes are marked obsolete.
+USING PERIODIC COMMIT
+LOAD CSV WITH HEADERS FROM %[3]s AS row
+MATCH (c:Class {id: row.id})
+SET c.obsolete = true;
+
+// Relabeled classes.
+USING PERIODIC COMMIT
+LOAD CSV WITH HEADERS FROM %[4]s AS row
+MATCH (c:Class {id: row.id})
+SET c.label = row.label;
+
+// Reparented classes.
+USING PERIODIC COMMIT
+LOAD CSV WITH HEADERS FROM %[5]s AS row
+MATCH (c:Class {id: row.id})-[r:subClassOf]->()
+DELETE r;
+
+USING PERIODIC COMMIT
+LOAD CSV WITH HEADERS FROM %[5]s AS row
+WITH row WHERE row.parents IS NOT NULL
+UNWIND split(row.parents, '|') AS pid
+MATCH (c:Class {id: row.id}), (p:Class {id: pid})
+MERGE (c)-[:subClassOf]->(p);
+`, quoteString(vocabID), fileURL("added"), fileURL("removed"), fileURL("relabeled"), fileURL("reparented"), propSet(props))
+
+	if len(types) > 0 {
+		b.WriteString("\n// Relations of added classes.\n")
+	}
+
+	for _, typ := range types {
+		fmt.Fprintf(&b, `USING PERIODIC COMMIT
+LOAD CSV WITH HEADERS FROM %s AS row
+WITH row WHERE row.type = %s
+MATCH (c:Class {id: row.source}), (t:Class {id: row.target})
+MERGE (c)-[:%s]->(t);
+`, fileURL("relations"), quoteString(typ), quoteName(typ))
+	}
+
+	return b.String(), nil
+}
+
+// propColumn returns the column of the added classes file holding a mapped
+// property.
+func propColumn(p propMapping) string {
+	name, _ := propValue(p.Property, "")
+	return "prop_" + name
+}
+
+// propSet returns the SET items of the mapped properties, converted the same
+// way as over Bolt.
+func propSet(props []propMapping) string {
+	var b strings.Builder
+
+	for _, p := range props {
+		name, v := propValue(p.Property, "")
+		col := "row." + quoteName(propColumn(p))
+
+		var expr string
+		switch v.(type) {
+		case []interface{}:
+			expr = fmt.Sprintf("[x IN split(coalesce(%s, ''), '|') WHERE x <> '']", col)
+		case bool:
+			expr = fmt.Sprintf("coalesce(%s, '') = 'true'", col)
+		default:
+			expr = fmt.Sprintf("coalesce(%s, '')", col)
+		}
+
+		fmt.Fprintf(&b, ",\n\tc.%s = %s", quoteName(name), expr)
+	}
+
+	return b.String()
+}
+
+// quoteString quotes a string literal for use in a Cypher statement.
+func quoteString(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `'`, `\'`, -1)
+	return "'" + s + "'"
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffClasses(t *testing.T) {
+	prev := []*class{
+		{ID: "a", Label: "A"},
+		{ID: "b", Label: "B", Parents: []string{"a"}},
+		{ID: "c", Label: "C", Parents: []string{"a", "b"}},
+	}
+
+	next := []*class{
+		{ID: "a", Label: "A"},
+		{ID: "c", Label: "C2", Parents: []string{"b", "a"}},
+		{ID: "d", Label: "D", Parents: []string{"c"}},
+		{ID: "b", Label: "B", Parents: []string{"d"}},
+	}
+
+	d := diffClasses(prev, next)
+
+	if len(d.Added) != 1 || d.Added[0].ID != "d" {
+		t.Errorf("unexpected added: %v", d.Added)
+	}
+
+	if len(d.Removed) != 0 {
+		t.Errorf("unexpected removed: %v", d.Removed)
+	}
+
+	if len(d.Relabeled) != 1 || d.Relabeled[0].OldLabel != "C" {
+		t.Errorf("unexpected relabeled: %v", d.Relabeled)
+	}
+
+	// Parent order does not matter.
+	if len(d.Reparented) != 1 || d.Reparented[0].Class.ID != "b" {
+		t.Errorf("unexpected reparented: %v", d.Reparented)
+	}
+
+	d = diffClasses(next, prev)
+
+	if len(d.Removed) != 1 || d.Removed[0].ID != "d" {
+		t.Errorf("unexpected removed: %v", d.Removed)
+	}
+}
+
+func TestWriteDiffCSV(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	props := []propMapping{
+		{Column: "Notation", Property: "notation"},
+		{Column: "alt_id", Property: "altIds:string[]"},
+	}
+
+	d := diffClasses(nil, []*class{{
+		ID:        "a",
+		Label:     "A",
+		Props:     []string{"A.1", "x|y"},
+		Relations: []relation{{Type: "part_of", Target: "b"}},
+	}})
+
+	script, err := writeDiffCSV(dir, `o'brien\`, props, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, `o'brien\_diff_added.csv`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Mapped properties are kept as over Bolt.
+	if !strings.HasSuffix(string(b), ",A.1,x|y\n") || !strings.Contains(string(b), ",prop_notation,prop_altIds\n") {
+		t.Errorf("unexpected added classes:\n%s", b)
+	}
+
+	for _, set := range []string{
+		"c.`notation` = coalesce(row.`prop_notation`, '')",
+		"c.`altIds` = [x IN split(coalesce(row.`prop_altIds`, ''), '|') WHERE x <> '']",
+	} {
+		if !strings.Contains(script, set) {
+			t.Errorf("expected %s in script:\n%s", set, script)
+		}
+	}
+
+	if !strings.Contains(script, `MATCH (v:Vocabulary {id: 'o\'brien\\'})`) {
+		t.Errorf("vocabulary id not escaped:\n%s", script)
+	}
+
+	b, err = ioutil.ReadFile(filepath.Join(dir, `o'brien\_diff_relations.csv`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != "source,type,target\na,part_of,b\n" {
+		t.Errorf("unexpected relations:\n%s", b)
+	}
+
+	if !strings.Contains(script, "WITH row WHERE row.type = 'part_of'") || !strings.Contains(script, "MERGE (c)-[:`part_of`]->(t);") {
+		t.Errorf("expected part_of relations in script:\n%s", script)
+	}
+
+	// Files are referred to relative to the import directory.
+	if !strings.Contains(script, `FROM 'file:///o%27brien%5C_diff_added.csv' AS row`) {
+		t.Errorf("unexpected file URL:\n%s", script)
+	}
+
+	if strings.Contains(script, dir) {
+		t.Errorf("output directory in script:\n%s", script)
+	}
+}
+
+func TestDiffReleasesUnchanged(t *testing.T) {
+	dir, err := ioutil.TempDir("", "diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The dangling owl#Thing parent is dropped by lenient validation.
+	path := filepath.Join(dir, "t.csv")
+	data := "Class ID,Preferred Label,Parents\nhttp://x/a,A,http://www.w3.org/2002/07/owl#Thing\nhttp://x/b,B,http://x/a\n"
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, mode := range []string{validateOff, validateLenient} {
+		v := &vocab{ID: "t", Source: path}
+
+		d, err := diffReleases(v, path, &options{validate: mode})
+		if err != nil {
+			t.Fatalf("%s: %s", mode, err)
+		}
+
+		if d.String() != "0 added, 0 removed, 0 relabeled, 0 reparented" {
+			t.Errorf("%s: expected no changes, got %s", mode, d)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/chop-dbhi/go-bioportal"
+)
+
+// fetch downloads the CSV export of the latest submission of the ontology
+// into dir, sets it as the vocabulary's source and records the submission.
+func fetch(c *bioportal.Client, v *vocab, dir string) error {
+	sub, err := c.LatestSubmission(v.Fetch)
+	if err != nil {
+		return err
+	}
+
+	if v.Label == "" {
+		ont, err := c.Ontology(v.Fetch)
+		if err != nil {
+			return err
+		}
+		v.Label = ont.Name
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("%s_source_%d.csv.gz", v.ID, sub.SubmissionID))
+
+	// Reuse a previous download of the same submission.
+	if _, err := os.Stat(path); err == nil {
+		log.Printf("%s: using %s", v.ID, path)
+	} else {
+		log.Printf("%s: downloading %s submission %d", v.ID, v.Fetch, sub.SubmissionID)
+
+		f, err := os.Create(path + ".part")
+		if err != nil {
+			return err
+		}
+
+		_, err = c.DownloadSubmission(f, v.Fetch, sub.SubmissionID, bioportal.DownloadOptions{Format: "csv"})
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(f.Name())
+			return err
+		}
+
+		if err := os.Rename(f.Name(), path); err != nil {
+			return err
+		}
+	}
+
+	v.Source = path
+	v.Submission = &submission{
+		ID:       sub.SubmissionID,
+		Version:  sub.Version,
+		Released: sub.Released,
+	}
+
+	// Only keep the date of the release timestamp.
+	if i := strings.Index(v.Submission.Released, "T"); i > 0 {
+		v.Submission.Released = v.Submission.Released[:i]
+	}
+
+	return nil
+}
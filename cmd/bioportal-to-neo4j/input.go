@@ -0,0 +1,167 @@
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Input formats.
+const (
+	formatCSV    = "csv"
+	formatOWL    = "owl"
+	formatTurtle = "ttl"
+	formatOBO    = "obo"
+	formatRRF    = "rrf"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte("PK\x03\x04")
+)
+
+// inputFile is a decompressed view of an input file.
+type inputFile struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (f *inputFile) Close() error {
+	var err error
+	for i := len(f.closers) - 1; i >= 0; i-- {
+		if cerr := f.closers[i].Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// openInput opens a file, or standard input if path is "-". Gzipped files
+// are decompressed transparently and the single file, or first CSV file, of
+// a zip archive is read.
+func openInput(path string) (*inputFile, error) {
+	var f *os.File
+
+	if path == "-" {
+		f = os.Stdin
+	} else {
+		var err error
+		if f, err = os.Open(path); err != nil {
+			return nil, err
+		}
+	}
+
+	in := &inputFile{}
+
+	// Standard input is left open.
+	if f != os.Stdin {
+		in.closers = append(in.closers, f)
+	}
+
+	br := bufio.NewReader(f)
+	in.Reader = br
+
+	magic, err := br.Peek(len(zipMagic))
+	if err != nil && err != io.EOF {
+		in.Close()
+		return nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			in.Close()
+			return nil, err
+		}
+
+		in.Reader = gr
+		in.closers = append(in.closers, gr)
+
+	case bytes.HasPrefix(magic, zipMagic):
+		if f == os.Stdin {
+			in.Close()
+			return nil, errors.New("zip archives cannot be read from standard input")
+		}
+
+		rc, err := openZipEntry(f)
+		if err != nil {
+			in.Close()
+			return nil, err
+		}
+
+		in.Reader = rc
+		in.closers = append(in.closers, rc)
+	}
+
+	return in, nil
+}
+
+// openZipEntry opens the CSV file contained in the zip archive.
+func openZipEntry(f *os.File) (io.ReadCloser, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	zr, err := zip.NewReader(f, info.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*zip.File
+	for _, zf := range zr.File {
+		if !zf.FileInfo().IsDir() {
+			files = append(files, zf)
+		}
+	}
+
+	switch len(files) {
+	case 0:
+		return nil, errors.New("zip archive is empty")
+	case 1:
+		return files[0].Open()
+	}
+
+	for _, zf := range files {
+		if strings.EqualFold(path.Ext(zf.Name), ".csv") {
+			return zf.Open()
+		}
+	}
+
+	return nil, fmt.Errorf("zip archive contains %d files and none is a CSV file", len(files))
+}
+
+// detectFormat returns the input format matching the file extension,
+// ignoring any compression extension. Directories are assumed to hold UMLS
+// RRF files. Defaults to CSV.
+func detectFormat(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return formatRRF
+	}
+
+	name := strings.ToLower(path)
+	name = strings.TrimSuffix(name, ".gz")
+	name = strings.TrimSuffix(name, ".zip")
+
+	switch filepath.Ext(name) {
+	case ".owl", ".rdf", ".xml":
+		return formatOWL
+	case ".ttl":
+		return formatTurtle
+	case ".obo":
+		return formatOBO
+	case ".rrf":
+		return formatRRF
+	}
+
+	return formatCSV
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const inputCSV = "Class ID,Preferred Label\nhttp://x/A,Alpha\n"
+
+func TestOpenInput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "input")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	plain := filepath.Join(dir, "v.csv")
+	if err := ioutil.WriteFile(plain, []byte(inputCSV), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	gz := filepath.Join(dir, "v.csv.gz")
+	f, err := os.Create(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gw := gzip.NewWriter(f)
+	gw.Write([]byte(inputCSV))
+	gw.Close()
+	f.Close()
+
+	zp := filepath.Join(dir, "v.zip")
+	f, err = os.Create(zp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, _ := zw.Create("notes.txt")
+	w.Write([]byte("ignored"))
+	w, _ = zw.Create("v.csv")
+	w.Write([]byte(inputCSV))
+	zw.Close()
+	f.Close()
+
+	for _, path := range []string{plain, gz, zp} {
+		in, err := openInput(path)
+		if err != nil {
+			t.Errorf("%s: %s", path, err)
+			continue
+		}
+
+		b, err := ioutil.ReadAll(in)
+		in.Close()
+
+		if err != nil {
+			t.Errorf("%s: %s", path, err)
+		} else if string(b) != inputCSV {
+			t.Errorf("%s: unexpected content %q", path, b)
+		}
+	}
+}
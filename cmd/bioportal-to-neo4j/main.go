@@ -1,177 +1,383 @@
 package main
 
 import (
-	"encoding/csv"
+	"flag"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"os"
-	"strings"
-)
 
-var vocabHeader = []string{
-	"id:ID(Vocabulary)",
-	"label",
-}
+	"github.com/chop-dbhi/go-bioportal"
+)
 
-var classHeader = []string{
-	"id:ID(Class)",
-	"label",
-	"code",
-	"synonyms:string[]",
-	"definitions:string[]",
-	"obsolete:boolean",
-	"cui:string[]",
-	"semanticTypes:string[]",
-}
+const usage = `usage: bioportal-to-neo4j [flags] <vocab-id> <vocab-label> <vocab-file>
+       bioportal-to-neo4j [flags] -fetch <acronym> <vocab-id> [<vocab-label>]
+       bioportal-to-neo4j [flags] -manifest <file>
 
-var subClassOfRelHeader = []string{
-	":START_ID(Class)",
-	":TYPE",
-	":END_ID(Class)",
-}
+Flags:
+`
 
-var classOfRelHeader = []string{
-	":START_ID(Class)",
-	":TYPE",
-	":END_ID(Vocabulary)",
-}
+// options are the settings shared by all vocabularies in a run.
+type options struct {
+	outDir    string
+	boltURL   string
+	batchSize int
+	validate  string
+	format    string
+	compress  bool
+	progress  int
 
-func newFile(name string, args ...interface{}) *os.File {
-	f, err := os.Create(fmt.Sprintf(name, args...))
-	if err != nil {
-		log.Fatal(f)
-	}
-	return f
+	// written is shared by the CSV writers of a manifest's vocabularies.
+	written map[string]string
 }
 
 func main() {
 	log.SetFlags(0)
 
-	args := os.Args[1:]
-	if len(args) < 3 {
-		log.Fatal("vocabulary id, label, and path required")
+	var (
+		opts         options
+		props        propFlag
+		reportPath   string
+		prevPath     string
+		manifestPath string
+		sab          string
+		fetchAcronym string
+		apiKey       string
+	)
+
+	flag.StringVar(&manifestPath, "manifest", "", "JSON manifest listing the vocabularies to import.")
+	flag.StringVar(&fetchAcronym, "fetch", "", "Download the latest CSV export of this BioPortal ontology instead of reading a file.")
+	flag.StringVar(&apiKey, "key", os.Getenv("BIOPORTAL_API_KEY"), "BioPortal API key used with -fetch. Defaults to $BIOPORTAL_API_KEY.")
+	flag.StringVar(&opts.outDir, "out", ".", "Directory the output files are written to.")
+	flag.StringVar(&prevPath, "previous", "", "Previously loaded release of the vocabulary. Only the changes from it are emitted, or applied with -bolt.")
+	flag.StringVar(&opts.boltURL, "bolt", "", "Load into a running Neo4j at this Bolt address instead of writing CSV files.")
+	flag.IntVar(&opts.batchSize, "batch", DefaultBatchSize, "Number of rows per statement when loading over Bolt.")
+	flag.StringVar(&opts.format, "format", "", "Input format: csv, owl (RDF/XML), ttl (Turtle), obo or rrf (UMLS). Detected from the file extension by default.")
+	flag.StringVar(&opts.validate, "validate", validateLenient, "Validation mode: strict fails on any issue, lenient drops bad rows and edges, off skips validation.")
+	flag.StringVar(&sab, "sab", "", "UMLS source abbreviation to load from RRF files, e.g. ICD10CM.")
+	flag.BoolVar(&opts.compress, "gzip", false, "Write gzipped CSV files.")
+	flag.IntVar(&opts.progress, "progress", 100000, "Log progress every this many rows. Zero disables it.")
+	flag.StringVar(&reportPath, "report", "", "Write every validation issue to this CSV file.")
+	flag.Var(&props, "prop", "Map an additional column onto a class property as <column>=<property>. The property may include a type, e.g. \"Notation=notation:string\". May be repeated.")
+
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
 	}
 
-	vocabID := args[0]
-	vocabLabel := args[1]
-	vocabPath := args[2]
+	flag.Parse()
 
-	vf, err := os.Open(vocabPath)
-	if err != nil {
+	switch opts.validate {
+	case validateOff, validateLenient, validateStrict:
+	default:
+		log.Fatalf("invalid validation mode %q", opts.validate)
+	}
+
+	switch opts.format {
+	case "", formatCSV, formatOWL, formatTurtle, formatOBO, formatRRF:
+	default:
+		log.Fatalf("invalid input format %q", opts.format)
+	}
+
+	if err := os.MkdirAll(opts.outDir, 0755); err != nil {
 		log.Fatal(err)
 	}
-	defer vf.Close()
 
-	cr := csv.NewReader(vf)
-	_, err = cr.Read()
+	var vocabs []*vocab
+
+	if manifestPath != "" {
+		if prevPath != "" {
+			log.Fatal("-previous cannot be used with -manifest")
+		}
+
+		m, err := readManifest(manifestPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		vocabs = m.Vocabularies
+	} else if fetchAcronym != "" {
+		args := flag.Args()
+		if len(args) < 1 {
+			log.Fatal("vocabulary id required")
+		}
+
+		v := &vocab{
+			ID:     args[0],
+			Fetch:  fetchAcronym,
+			Props:  props,
+			Report: reportPath,
+		}
+
+		if len(args) > 1 {
+			v.Label = args[1]
+		}
+
+		vocabs = []*vocab{v}
+	} else {
+		args := flag.Args()
+		if len(args) < 3 {
+			log.Fatal("vocabulary id, label, and path required")
+		}
+
+		vocabs = []*vocab{{
+			ID:     args[0],
+			Label:  args[1],
+			Source: args[2],
+			SAB:    sab,
+			Props:  props,
+			Report: reportPath,
+		}}
+	}
+
+	for _, v := range vocabs {
+		if v.Fetch == "" {
+			continue
+		}
+
+		if apiKey == "" {
+			log.Fatal("API key required to fetch from BioPortal")
+		}
+
+		if err := fetch(bioportal.NewClient(apiKey), v, opts.outDir); err != nil {
+			log.Fatalf("%s: %s", v.ID, err)
+		}
+	}
+
+	if prevPath != "" {
+		if err := update(vocabs[0], prevPath, &opts); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	var writers []*csvWriter
+
+	// Vocabularies may share classes, such as imported terms.
+	if len(vocabs) > 1 && opts.boltURL == "" {
+		opts.written = make(map[string]string)
+	}
+
+	for _, v := range vocabs {
+		w, err := load(v, &opts)
+		if err != nil {
+			log.Fatalf("%s: %s", v.ID, err)
+		}
+
+		if cw, ok := w.(*csvWriter); ok {
+			writers = append(writers, cw)
+		}
+	}
+
+	if len(writers) > 0 {
+		fmt.Print(importScript(writers))
+	}
+}
+
+// open returns a reader over the classes of the vocabulary. If validation
+// is enabled the classes are read and validated up front.
+func open(v *vocab, opts *options) (classReader, io.Closer, error) {
+	format := v.Format
+	if format == "" {
+		format = opts.format
+	}
+	if format == "" {
+		format = detectFormat(v.Source)
+	}
+
+	// RRF files are read from a directory.
+	var f io.Closer = ioutil.NopCloser(nil)
+
+	var (
+		in  *inputFile
+		err error
+	)
+
+	if format != formatRRF {
+		if in, err = openInput(v.Source); err != nil {
+			return nil, nil, err
+		}
+		f = in
+	}
+
+	var src classReader
+
+	switch format {
+	case formatRRF:
+		src, err = newRRFReader(v.Source, v.SAB, v.Props)
+	case formatCSV:
+		if src, err = newCSVReader(in, v.Props); err != nil {
+			err = fmt.Errorf("error reading header: %s", err)
+		}
+	case formatOWL, formatTurtle:
+		src, err = newRDFReader(in, format, v.Props)
+	case formatOBO:
+		src, err = newOBOReader(in, v.Props)
+	default:
+		err = fmt.Errorf("invalid input format %q", format)
+	}
 	if err != nil {
-		log.Fatalf("error reading row: %s", err)
+		f.Close()
+		return nil, nil, err
 	}
 
-	f := newFile("%s_nodes_vocabulary.csv", vocabID)
+	r := newProgressReader(src, v.ID, opts.progress)
+
+	mode := v.Validate
+	if mode == "" {
+		mode = opts.validate
+	}
+
+	if mode == validateOff {
+		return r, f, nil
+	}
 
-	cw := csv.NewWriter(f)
-	err = cw.WriteAll([][]string{
-		vocabHeader,
-		[]string{vocabID, vocabLabel},
-	})
+	classes, err := readAll(r)
 	if err != nil {
-		log.Fatal(err)
+		f.Close()
+		return nil, nil, fmt.Errorf("error reading row: %s", err)
 	}
-	cw.Flush()
-	f.Close()
 
-	f = newFile("%s_nodes_class.csv", vocabID)
-	defer f.Close()
-	cw = csv.NewWriter(f)
-	defer cw.Flush()
-	if err := cw.Write(classHeader); err != nil {
-		log.Fatal(err)
+	var rep *report
+	classes, rep = validate(classes, mode == validateLenient)
+
+	if len(rep.Issues) > 0 {
+		log.Printf("%s: %d validation issues", v.ID, len(rep.Issues))
+		rep.Summary(os.Stderr)
 	}
 
-	vrf := newFile("%s_rels_classof.csv", vocabID)
-	defer vrf.Close()
-	vrcw := csv.NewWriter(vrf)
-	defer vrcw.Flush()
-	if err := vrcw.Write(classOfRelHeader); err != nil {
-		log.Fatal(err)
+	if v.Report != "" {
+		if err := writeReport(v.Report, rep); err != nil {
+			f.Close()
+			return nil, nil, err
+		}
 	}
 
-	rf := newFile("%s_rels_subclassof.csv", vocabID)
-	defer rf.Close()
-	rcw := csv.NewWriter(rf)
-	defer rcw.Flush()
-	if err := rcw.Write(subClassOfRelHeader); err != nil {
-		log.Fatal(err)
+	if mode == validateStrict && len(rep.Issues) > 0 {
+		f.Close()
+		return nil, nil, fmt.Errorf("validation failed with %d issues", len(rep.Issues))
+	}
+
+	return &sliceReader{classes: classes}, f, nil
+}
+
+// load reads the vocabulary and writes it to CSV files or over Bolt.
+func load(v *vocab, opts *options) (classWriter, error) {
+	src, f, err := open(v, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var w classWriter
+
+	if opts.boltURL != "" {
+		w, err = newBoltWriter(opts.boltURL, v, opts.batchSize)
+	} else {
+		w, err = newCSVWriter(opts.outDir, v, opts.compress, opts.written)
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	for {
-		row, err := cr.Read()
+		c, err := src.Read()
 		if err == io.EOF {
 			break
 		} else if err != nil {
-			log.Fatalf("error reading row: %s", err)
+			w.Close()
+			return nil, fmt.Errorf("error reading row: %s", err)
 		}
 
-		id := row[0]
-		if id == "" {
-			log.Printf("skipping: %v", row)
-			continue
+		if err := w.Write(c); err != nil {
+			w.Close()
+			return nil, err
 		}
+	}
 
-		var parents []string
-		if row[7] != "" {
-			parents = strings.Split(row[7], "|")
-		}
+	return w, w.Close()
+}
 
-		toks := strings.Split(id, "/")
-		code := toks[len(toks)-1]
+// update compares the vocabulary with the previous release and either
+// applies the changes over Bolt or writes them out with a Cypher script.
+func update(v *vocab, prevPath string, opts *options) error {
+	d, err := diffReleases(v, prevPath, opts)
+	if err != nil {
+		return err
+	}
 
-		err = cw.Write([]string{
-			id,
-			row[1],
-			code,
-			row[2],
-			row[3],
-			row[4],
-			row[5],
-			row[6],
-		})
+	log.Printf("%s: %s", v.ID, d)
 
+	if opts.boltURL != "" {
+		w, err := newBoltWriter(opts.boltURL, v, opts.batchSize)
 		if err != nil {
-			log.Fatal(err)
+			return err
 		}
 
-		err = vrcw.Write([]string{
-			id,
-			"classOf",
-			vocabID,
-		})
-		if err != nil {
-			log.Fatal(err)
+		if err := w.Apply(d); err != nil {
+			w.Close()
+			return err
 		}
 
-		for _, pid := range parents {
-			err = rcw.Write([]string{
-				id,
-				"subClassOf",
-				pid,
-			})
-			if err != nil {
-				log.Fatal(err)
-			}
-		}
-	}
-
-	fmt.Printf(`./neo4j/bin/neo4j-import \
-	--into ./neo4j/data/databases/graph.db \
-	--delimiter ',' \
-	--array-delimiter '|' \
-	--quote '"' \
-	--nodes:Vocabulary "%s_nodes_vocabulary.csv" \
-	--nodes:Class "%s_nodes_class.csv" \
-	--relationships "%s_rels_subclassof.csv" \
-	--relationships "%s_rels_classof.csv"
-	`, vocabID, vocabID, vocabID, vocabID)
+		return w.Close()
+	}
+
+	script, err := writeDiffCSV(opts.outDir, v.ID, v.Props, d)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(script)
+	return nil
+}
+
+// diffReleases reads the vocabulary and its previous release and compares
+// them. Both are validated in the same mode, so edges dropped by lenient
+// validation are not reported as changes.
+func diffReleases(v *vocab, prevPath string, opts *options) (*vocabDiff, error) {
+	src, f, err := open(v, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	classes, err := readAll(src)
+	if err != nil {
+		return nil, err
+	}
+
+	prevSrc, pf, err := open(&vocab{
+		ID:       v.ID,
+		Source:   prevPath,
+		Format:   v.Format,
+		SAB:      v.SAB,
+		Props:    v.Props,
+		Validate: v.Validate,
+	}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("previous release: %s", err)
+	}
+	defer pf.Close()
+
+	prev, err := readAll(prevSrc)
+	if err != nil {
+		return nil, fmt.Errorf("previous release: %s", err)
+	}
+
+	return diffClasses(prev, classes), nil
+}
+
+func writeReport(path string, r *report) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := r.WriteCSV(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
 }
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// vocab describes a vocabulary to import and how to read it.
+type vocab struct {
+	ID     string `json:"id"`
+	Label  string `json:"label"`
+	Source string `json:"source"`
+
+	// Fetch is a BioPortal ontology acronym whose latest submission is
+	// downloaded and used as the source.
+	Fetch string `json:"fetch"`
+
+	// Format is the format of the source. It is detected from the file
+	// extension if not set.
+	Format string `json:"format"`
+
+	// SAB is the UMLS source abbreviation read from RRF files.
+	SAB string `json:"sab"`
+
+	// Props maps additional columns onto class properties.
+	Props []propMapping `json:"props"`
+
+	// Validate is the validation mode. Defaults to the -validate flag.
+	Validate string `json:"validate"`
+
+	// Report is the file validation issues are written to.
+	Report string `json:"report"`
+
+	// Submission is set when the source was fetched from BioPortal.
+	Submission *submission `json:"-"`
+}
+
+// submission identifies the BioPortal release a vocabulary was loaded from.
+type submission struct {
+	ID       int
+	Version  string
+	Released string
+}
+
+// manifest lists the vocabularies imported together.
+//
+//	{
+//	  "vocabularies": [
+//	    {"id": "icd10cm", "label": "ICD-10-CM", "source": "icd10cm.csv"},
+//	    {"id": "hp", "label": "Human Phenotype Ontology", "source": "hp.csv", "validate": "strict"}
+//	  ]
+//	}
+type manifest struct {
+	Vocabularies []*vocab `json:"vocabularies"`
+}
+
+// readManifest reads a JSON manifest. Relative source and report paths are
+// resolved against the manifest's directory.
+func readManifest(path string) (*manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var m manifest
+	if err := json.NewDecoder(f).Decode(&m); err != nil {
+		return nil, fmt.Errorf("error reading manifest: %s", err)
+	}
+
+	dir := filepath.Dir(path)
+	ids := make(map[string]struct{})
+
+	for i, v := range m.Vocabularies {
+		if v.ID == "" || (v.Source == "") == (v.Fetch == "") {
+			return nil, fmt.Errorf("vocabulary %d: id and either source or fetch required", i)
+		}
+
+		if _, ok := ids[v.ID]; ok {
+			return nil, fmt.Errorf("vocabulary %s listed more than once", v.ID)
+		}
+		ids[v.ID] = struct{}{}
+
+		if v.Label == "" && v.Fetch == "" {
+			v.Label = v.ID
+		}
+
+		if v.Source != "" && v.Source != "-" && !filepath.IsAbs(v.Source) {
+			v.Source = filepath.Join(dir, v.Source)
+		}
+
+		if v.Report != "" && !filepath.IsAbs(v.Report) {
+			v.Report = filepath.Join(dir, v.Report)
+		}
+
+		switch v.Validate {
+		case "", validateOff, validateLenient, validateStrict:
+		default:
+			return nil, fmt.Errorf("vocabulary %s: invalid validation mode %q", v.ID, v.Validate)
+		}
+	}
+
+	return &m, nil
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// oboReader reads the [Term] stanzas of an OBO 1.4 file as classes. is_a
+// tags become parents and relationship tags become typed relations. The
+// values of other tags can be mapped onto properties by tag name.
+type oboReader struct {
+	sc    *bufio.Scanner
+	props []propMapping
+
+	ontology string
+	idspaces map[string]string
+
+	// stanza is the header of the next stanza, read while scanning the
+	// previous one.
+	stanza string
+}
+
+// oboTag is a tag-value pair of a stanza.
+type oboTag struct {
+	name  string
+	value string
+}
+
+func newOBOReader(r io.Reader, props []propMapping) (*oboReader, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	o := &oboReader{
+		sc:       sc,
+		props:    props,
+		idspaces: make(map[string]string),
+	}
+
+	header, err := o.readStanza()
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	for _, t := range header {
+		switch t.name {
+		case "ontology":
+			o.ontology = t.value
+		case "idspace":
+			// idspace: GO http://purl.obolibrary.org/obo/GO_ "description"
+			if f := strings.Fields(t.value); len(f) >= 2 {
+				o.idspaces[f[0]] = f[1]
+			}
+		}
+	}
+
+	return o, nil
+}
+
+// readStanza reads the tags up to the next stanza header. It returns
+// io.EOF with the last stanza's tags at the end of the input.
+func (o *oboReader) readStanza() ([]*oboTag, error) {
+	var tags []*oboTag
+
+	for o.sc.Scan() {
+		line := strings.TrimSpace(o.sc.Text())
+
+		if line == "" || line[0] == '!' {
+			continue
+		}
+
+		if line[0] == '[' && line[len(line)-1] == ']' {
+			o.stanza = line
+			return tags, nil
+		}
+
+		i := strings.Index(line, ":")
+		if i < 0 {
+			continue
+		}
+
+		tags = append(tags, &oboTag{
+			name:  strings.TrimSpace(line[:i]),
+			value: strings.TrimSpace(line[i+1:]),
+		})
+	}
+
+	if err := o.sc.Err(); err != nil {
+		return nil, err
+	}
+
+	return tags, io.EOF
+}
+
+// Read returns the next term or io.EOF. Other stanza types are skipped.
+func (o *oboReader) Read() (*class, error) {
+	for {
+		if o.stanza == "" {
+			return nil, io.EOF
+		}
+
+		stanza := o.stanza
+		o.stanza = ""
+
+		tags, err := o.readStanza()
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		if stanza != "[Term]" {
+			continue
+		}
+
+		if c := o.term(tags); c != nil {
+			return c, nil
+		}
+	}
+}
+
+func (o *oboReader) term(tags []*oboTag) *class {
+	c := &class{
+		Obsolete: "false",
+		Props:    make([]string, len(o.props)),
+	}
+
+	for _, t := range tags {
+		v := oboStripComment(t.value)
+
+		switch t.name {
+		case "id":
+			c.ID = o.iri(v)
+		case "name":
+			c.Label = oboUnescape(v)
+		case "synonym":
+			if s, ok := oboQuoted(v); ok {
+				c.Synonyms = appendUnique(c.Synonyms, s)
+			}
+		case "def":
+			if s, ok := oboQuoted(v); ok {
+				c.Definitions = append(c.Definitions, s)
+			}
+		case "is_obsolete":
+			if v == "true" {
+				c.Obsolete = "true"
+			}
+		case "is_a":
+			if f := strings.Fields(v); len(f) > 0 {
+				c.Parents = appendUnique(c.Parents, o.iri(f[0]))
+			}
+		case "relationship":
+			f := strings.Fields(v)
+			if len(f) < 2 {
+				continue
+			}
+
+			if f[0] == "is_a" {
+				c.Parents = appendUnique(c.Parents, o.iri(f[1]))
+			} else {
+				c.Relations = append(c.Relations, relation{
+					Type:   f[0],
+					Target: o.iri(f[1]),
+				})
+			}
+		}
+
+		for i, p := range o.props {
+			if p.Column != t.name {
+				continue
+			}
+
+			if c.Props[i] != "" {
+				c.Props[i] += "|"
+			}
+
+			if s, ok := oboQuoted(v); ok {
+				c.Props[i] += s
+			} else {
+				c.Props[i] += oboUnescape(v)
+			}
+		}
+	}
+
+	if c.ID == "" {
+		return nil
+	}
+
+	c.Code = codeFromID(c.ID)
+
+	return c
+}
+
+// iri translates an OBO identifier into the IRI used for it in the OWL
+// version of the ontology, so the classes match those of a BioPortal CSV.
+// Prefixed IDs such as GO:0008150 become OBO PURLs unless the prefix is
+// declared with idspace, and unprefixed IDs are local to the ontology.
+func (o *oboReader) iri(id string) string {
+	if strings.Contains(id, "://") {
+		return id
+	}
+
+	i := strings.Index(id, ":")
+	if i < 0 {
+		return nsOBO + o.ontology + "#" + id
+	}
+
+	prefix, local := id[:i], id[i+1:]
+
+	if ns, ok := o.idspaces[prefix]; ok {
+		return ns + local
+	}
+
+	return nsOBO + prefix + "_" + local
+}
+
+// oboStripComment removes a trailing "! comment" and "{modifiers}" from a
+// tag value. Escaped and quoted exclamation marks are kept.
+func oboStripComment(v string) string {
+	quoted := false
+
+scan:
+	for i := 0; i < len(v); i++ {
+		switch v[i] {
+		case '\\':
+			i++
+		case '"':
+			quoted = !quoted
+		case '!':
+			if !quoted {
+				v = strings.TrimSpace(v[:i])
+				break scan
+			}
+		}
+	}
+
+	if strings.HasSuffix(v, "}") {
+		if i := strings.LastIndex(v, "{"); i > 0 && !strings.Contains(v[i:], "\"") {
+			v = strings.TrimSpace(v[:i])
+		}
+	}
+
+	return v
+}
+
+// oboQuoted returns the leading quoted string of a value such as a def or
+// synonym.
+func oboQuoted(v string) (string, bool) {
+	if !strings.HasPrefix(v, "\"") {
+		return "", false
+	}
+
+	for i := 1; i < len(v); i++ {
+		switch v[i] {
+		case '\\':
+			i++
+		case '"':
+			return oboUnescape(v[1:i]), true
+		}
+	}
+
+	return "", false
+}
+
+func oboUnescape(v string) string {
+	if !strings.Contains(v, "\\") {
+		return v
+	}
+
+	var b strings.Builder
+
+	for i := 0; i < len(v); i++ {
+		if v[i] != '\\' || i == len(v)-1 {
+			b.WriteByte(v[i])
+			continue
+		}
+
+		i++
+
+		switch v[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'W':
+			b.WriteByte(' ')
+		default:
+			b.WriteByte(v[i])
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testOBO = `format-version: 1.4
+ontology: go
+idspace: X http://example.org/x/ "example"
+
+[Term]
+id: GO:0008150
+name: biological_process
+
+[Term]
+id: GO:0009987
+name: cellular process
+def: "Any process \"carried out\" at the cellular level." [GOC:go_curators] ! see also
+synonym: "cell physiology" EXACT []
+synonym: "cell growth and/or maintenance" NARROW [] {source="GOC"}
+is_a: GO:0008150 ! biological_process
+relationship: part_of X:1 {source="GOC"} ! x
+xref: Wikipedia:Cell_(biology)
+xref: NIF:1
+
+[Typedef]
+id: part_of
+name: part of
+
+[Term]
+id: GO:0000001
+name: obsolete thing
+is_obsolete: true
+`
+
+func TestOBOReader(t *testing.T) {
+	r, err := newOBOReader(strings.NewReader(testOBO), []propMapping{
+		{Column: "xref", Property: "xrefs:string[]"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	classes, err := readAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(classes) != 3 {
+		t.Fatalf("expected 3 classes, got %d", len(classes))
+	}
+
+	exp := &class{
+		ID:          "http://purl.obolibrary.org/obo/GO_0009987",
+		Label:       "cellular process",
+		Code:        "GO_0009987",
+		Synonyms:    []string{"cell physiology", "cell growth and/or maintenance"},
+		Definitions: []string{`Any process "carried out" at the cellular level.`},
+		Obsolete:    "false",
+		Parents:     []string{"http://purl.obolibrary.org/obo/GO_0008150"},
+		Relations:   []relation{{Type: "part_of", Target: "http://example.org/x/1"}},
+		Props:       []string{"Wikipedia:Cell_(biology)|NIF:1"},
+	}
+
+	if !reflect.DeepEqual(classes[1], exp) {
+		t.Errorf("expected %+v, got %+v", exp, classes[1])
+	}
+
+	if classes[2].Obsolete != "true" {
+		t.Errorf("expected obsolete class, got %+v", classes[2])
+	}
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Namespaces and IRIs of the RDF vocabularies the OWL readers understand.
+const (
+	nsRDF       = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsRDFS      = "http://www.w3.org/2000/01/rdf-schema#"
+	nsOWL       = "http://www.w3.org/2002/07/owl#"
+	nsSKOS      = "http://www.w3.org/2004/02/skos/core#"
+	nsOBO       = "http://purl.obolibrary.org/obo/"
+	nsOBOInOWL  = "http://www.geneontology.org/formats/oboInOwl#"
+	nsUMLS      = "http://bioportal.bioontology.org/ontologies/umls/"
+	iriType     = nsRDF + "type"
+	iriOWLThing = nsOWL + "Thing"
+)
+
+// Predicates mapped onto the standard class fields.
+var (
+	rdfClassTypes = map[string]bool{
+		nsOWL + "Class":  true,
+		nsRDFS + "Class": true,
+	}
+
+	rdfPrefLabels = map[string]bool{
+		nsSKOS + "prefLabel": true,
+	}
+
+	rdfLabels = map[string]bool{
+		nsRDFS + "label": true,
+	}
+
+	rdfSynonyms = map[string]bool{
+		nsSKOS + "altLabel":              true,
+		nsOBOInOWL + "hasExactSynonym":   true,
+		nsOBOInOWL + "hasRelatedSynonym": true,
+		nsOBOInOWL + "hasBroadSynonym":   true,
+		nsOBOInOWL + "hasNarrowSynonym":  true,
+		nsOBOInOWL + "hasSynonym":        true,
+	}
+
+	rdfDefinitions = map[string]bool{
+		nsSKOS + "definition":        true,
+		nsOBO + "IAO_0000115":        true,
+		nsOBOInOWL + "hasDefinition": true,
+	}
+)
+
+// triple is an RDF statement. Blank nodes are prefixed with "_:".
+type triple struct {
+	S, P, O string
+
+	// Lit is true if the object is a literal, in which case Lang is its
+	// language tag, if any.
+	Lit  bool
+	Lang string
+}
+
+func isBlank(n string) bool {
+	return strings.HasPrefix(n, "_:")
+}
+
+// literal is a literal value with its language tag.
+type literal struct {
+	value string
+	lang  string
+}
+
+// rdfClass accumulates the statements about a class.
+type rdfClass struct {
+	isClass     bool
+	prefLabels  []literal
+	labels      []literal
+	synonyms    []string
+	definitions []string
+	cui         []string
+	semTypes    []string
+	parents     []string
+	deprecated  bool
+	props       [][]string
+}
+
+// rdfBuilder collects classes from a stream of triples. Statements about a
+// class may appear anywhere in the file, so all of them are kept until the
+// input is consumed.
+type rdfBuilder struct {
+	props   []propMapping
+	order   []string
+	classes map[string]*rdfClass
+}
+
+func newRDFBuilder(props []propMapping) *rdfBuilder {
+	return &rdfBuilder{
+		props:   props,
+		classes: make(map[string]*rdfClass),
+	}
+}
+
+func (b *rdfBuilder) get(s string) *rdfClass {
+	c, ok := b.classes[s]
+	if !ok {
+		c = &rdfClass{
+			props: make([][]string, len(b.props)),
+		}
+		b.classes[s] = c
+		b.order = append(b.order, s)
+	}
+	return c
+}
+
+// Add records a triple if it describes a named resource.
+func (b *rdfBuilder) Add(t *triple) {
+	if isBlank(t.S) {
+		return
+	}
+
+	switch {
+	case t.P == iriType && !t.Lit:
+		if rdfClassTypes[t.O] {
+			b.get(t.S).isClass = true
+		}
+
+	case t.P == nsRDFS+"subClassOf":
+		if !t.Lit && !isBlank(t.O) && t.O != iriOWLThing && t.O != t.S {
+			c := b.get(t.S)
+			c.parents = appendUnique(c.parents, t.O)
+		}
+
+	case t.P == nsOWL+"deprecated":
+		if t.Lit && (t.O == "true" || t.O == "1") {
+			b.get(t.S).deprecated = true
+		}
+
+	case rdfPrefLabels[t.P]:
+		c := b.get(t.S)
+		c.prefLabels = append(c.prefLabels, literal{t.O, t.Lang})
+
+	case rdfLabels[t.P]:
+		c := b.get(t.S)
+		c.labels = append(c.labels, literal{t.O, t.Lang})
+
+	case rdfSynonyms[t.P]:
+		c := b.get(t.S)
+		c.synonyms = appendUnique(c.synonyms, t.O)
+
+	case rdfDefinitions[t.P]:
+		c := b.get(t.S)
+		c.definitions = appendUnique(c.definitions, t.O)
+
+	case t.P == nsUMLS+"cui":
+		c := b.get(t.S)
+		c.cui = appendUnique(c.cui, t.O)
+
+	case t.P == nsUMLS+"hasSTY":
+		c := b.get(t.S)
+		c.semTypes = appendUnique(c.semTypes, codeFromID(t.O))
+	}
+
+	for i, p := range b.props {
+		if p.Column == t.P {
+			c := b.get(t.S)
+			c.props[i] = append(c.props[i], t.O)
+		}
+	}
+}
+
+// Classes returns the classes in the order they were first mentioned.
+func (b *rdfBuilder) Classes() []*class {
+	var classes []*class
+
+	for _, id := range b.order {
+		rc := b.classes[id]
+		if !rc.isClass {
+			continue
+		}
+
+		label := pickLabel(rc.prefLabels)
+		if label == "" {
+			label = pickLabel(rc.labels)
+		}
+
+		c := &class{
+			ID:            id,
+			Label:         label,
+			Code:          codeFromID(id),
+			Synonyms:      rc.synonyms,
+			Definitions:   rc.definitions,
+			Obsolete:      fmt.Sprint(rc.deprecated),
+			CUI:           rc.cui,
+			SemanticTypes: rc.semTypes,
+			Parents:       rc.parents,
+		}
+
+		for _, vals := range rc.props {
+			c.Props = append(c.Props, strings.Join(vals, "|"))
+		}
+
+		classes = append(classes, c)
+	}
+
+	return classes
+}
+
+// pickLabel returns the first English or untagged label, or the first label
+// if there is none.
+func pickLabel(ls []literal) string {
+	for _, l := range ls {
+		if l.lang == "" || strings.HasPrefix(strings.ToLower(l.lang), "en") {
+			return l.value
+		}
+	}
+
+	if len(ls) > 0 {
+		return ls[0].value
+	}
+
+	return ""
+}
+
+func appendUnique(s []string, v string) []string {
+	for _, x := range s {
+		if x == v {
+			return s
+		}
+	}
+	return append(s, v)
+}
+
+// newRDFReader reads all the classes of an RDF/XML or Turtle document.
+func newRDFReader(r io.Reader, format string, props []propMapping) (classReader, error) {
+	b := newRDFBuilder(props)
+
+	var err error
+	if format == formatTurtle {
+		err = parseTurtle(r, b.Add)
+	} else {
+		err = parseRDFXML(r, b.Add)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &sliceReader{classes: b.Classes()}, nil
+}
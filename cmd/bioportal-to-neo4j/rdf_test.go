@@ -0,0 +1,128 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testRDFXML = `<?xml version="1.0"?>
+<!DOCTYPE rdf:RDF [
+    <!ENTITY obo "http://purl.obolibrary.org/obo/" >
+]>
+<rdf:RDF xmlns="http://purl.obolibrary.org/obo/hp.owl#"
+     xml:base="http://purl.obolibrary.org/obo/hp.owl"
+     xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+     xmlns:rdfs="http://www.w3.org/2000/01/rdf-schema#"
+     xmlns:owl="http://www.w3.org/2002/07/owl#"
+     xmlns:skos="http://www.w3.org/2004/02/skos/core#"
+     xmlns:obo="http://purl.obolibrary.org/obo/"
+     xmlns:oboInOwl="http://www.geneontology.org/formats/oboInOwl#">
+    <owl:Ontology rdf:about="http://purl.obolibrary.org/obo/hp.owl"/>
+    <owl:Class rdf:about="&obo;HP_0000001">
+        <rdfs:label xml:lang="de">Alle</rdfs:label>
+        <rdfs:label xml:lang="en">All</rdfs:label>
+    </owl:Class>
+    <owl:Class rdf:about="&obo;HP_0000118">
+        <rdfs:subClassOf rdf:resource="&obo;HP_0000001"/>
+        <rdfs:subClassOf>
+            <owl:Restriction>
+                <owl:onProperty rdf:resource="&obo;BFO_0000050"/>
+                <owl:someValuesFrom rdf:resource="&obo;HP_0000002"/>
+            </owl:Restriction>
+        </rdfs:subClassOf>
+        <rdfs:label>Phenotypic abnormality</rdfs:label>
+        <skos:prefLabel>Phenotypic abnormality (preferred)</skos:prefLabel>
+        <oboInOwl:hasExactSynonym>Organ abnormality</oboInOwl:hasExactSynonym>
+        <obo:IAO_0000115>A phenotypic abnormality.</obo:IAO_0000115>
+    </owl:Class>
+    <rdf:Description rdf:about="&obo;HP_0000003">
+        <rdf:type rdf:resource="http://www.w3.org/2002/07/owl#Class"/>
+        <rdfs:subClassOf rdf:resource="&obo;HP_0000118"/>
+        <owl:deprecated rdf:datatype="http://www.w3.org/2001/XMLSchema#boolean">true</owl:deprecated>
+    </rdf:Description>
+    <owl:ObjectProperty rdf:about="&obo;BFO_0000050">
+        <rdfs:label>part of</rdfs:label>
+    </owl:ObjectProperty>
+</rdf:RDF>
+`
+
+const testTurtle = `@prefix : <http://purl.obolibrary.org/obo/> .
+@prefix rdfs: <http://www.w3.org/2000/01/rdf-schema#> .
+@prefix owl: <http://www.w3.org/2002/07/owl#> .
+@prefix skos: <http://www.w3.org/2004/02/skos/core#> .
+PREFIX oboInOwl: <http://www.geneontology.org/formats/oboInOwl#>
+
+# Classes
+:HP_0000001 a owl:Class ;
+    rdfs:label "Alle"@de, "All"@en .
+
+:HP_0000118 a owl:Class ;
+    rdfs:subClassOf :HP_0000001,
+        [ a owl:Restriction ;
+          owl:onProperty :BFO_0000050 ;
+          owl:someValuesFrom :HP_0000002 ] ;
+    rdfs:label "Phenotypic abnormality" ;
+    skos:prefLabel """Phenotypic abnormality (preferred)""" ;
+    oboInOwl:hasExactSynonym 'Organ abnormality' ;
+    :IAO_0000115 "A phenotypic \"abnormality\"."^^<http://www.w3.org/2001/XMLSchema#string> ;
+    .
+
+<http://purl.obolibrary.org/obo/HP_0000003> rdfs:subClassOf :HP_0000118 ;
+    owl:deprecated true ;
+    a owl:Class .
+
+:BFO_0000050 a owl:ObjectProperty ;
+    rdfs:label "part of" .
+`
+
+func TestRDFReader(t *testing.T) {
+	tests := []struct {
+		format string
+		input  string
+		def    string
+	}{
+		{formatOWL, testRDFXML, "A phenotypic abnormality."},
+		{formatTurtle, testTurtle, `A phenotypic "abnormality".`},
+	}
+
+	for _, test := range tests {
+		r, err := newRDFReader(strings.NewReader(test.input), test.format, nil)
+		if err != nil {
+			t.Fatalf("%s: %s", test.format, err)
+		}
+
+		classes, err := readAll(r)
+		if err != nil {
+			t.Fatalf("%s: %s", test.format, err)
+		}
+
+		if len(classes) != 3 {
+			t.Fatalf("%s: expected 3 classes, got %d", test.format, len(classes))
+		}
+
+		all, pa, dep := classes[0], classes[1], classes[2]
+
+		if all.Label != "All" || all.Code != "HP_0000001" {
+			t.Errorf("%s: unexpected class %+v", test.format, all)
+		}
+
+		exp := &class{
+			ID:          "http://purl.obolibrary.org/obo/HP_0000118",
+			Label:       "Phenotypic abnormality (preferred)",
+			Code:        "HP_0000118",
+			Synonyms:    []string{"Organ abnormality"},
+			Definitions: []string{test.def},
+			Obsolete:    "false",
+			Parents:     []string{"http://purl.obolibrary.org/obo/HP_0000001"},
+		}
+
+		if !reflect.DeepEqual(pa, exp) {
+			t.Errorf("%s: expected %+v, got %+v", test.format, exp, pa)
+		}
+
+		if dep.Obsolete != "true" || !reflect.DeepEqual(dep.Parents, []string{exp.ID}) {
+			t.Errorf("%s: unexpected class %+v", test.format, dep)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const nsXML = "http://www.w3.org/XML/1998/namespace"
+
+var entityDecl = regexp.MustCompile(`<!ENTITY\s+([^\s%]+)\s+(?:"([^"]*)"|'([^']*)')\s*>`)
+
+// xmlContext is the base IRI and language in scope for an element.
+type xmlContext struct {
+	base string
+	lang string
+}
+
+// rdfXMLParser emits the triples of an RDF/XML document. It supports the
+// parts of the syntax used by OWL ontologies: typed node elements, property
+// attributes, rdf:resource and rdf:nodeID references, nested node elements
+// and the Resource, Literal and Collection parse types.
+type rdfXMLParser struct {
+	d      *xml.Decoder
+	emit   func(*triple)
+	blanks int
+}
+
+func parseRDFXML(r io.Reader, emit func(*triple)) error {
+	d := xml.NewDecoder(r)
+	d.Strict = false
+	d.Entity = make(map[string]string)
+
+	p := &rdfXMLParser{
+		d:    d,
+		emit: emit,
+	}
+
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.Directive:
+			p.entities(t)
+
+		case xml.StartElement:
+			ctx := p.context(t, xmlContext{})
+
+			if t.Name.Space == nsRDF && t.Name.Local == "RDF" {
+				err = p.nodeElements(ctx)
+			} else {
+				_, err = p.nodeElement(t, ctx)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// entities registers the entities declared in the DOCTYPE, which OWL files
+// commonly use to abbreviate namespaces in attribute values.
+func (p *rdfXMLParser) entities(d xml.Directive) {
+	for _, m := range entityDecl.FindAllStringSubmatch(string(d), -1) {
+		v := m[2]
+		if v == "" {
+			v = m[3]
+		}
+		p.d.Entity[m[1]] = v
+	}
+}
+
+func (p *rdfXMLParser) blank() string {
+	p.blanks++
+	return "_:#" + strconv.Itoa(p.blanks)
+}
+
+// context applies the xml:base and xml:lang attributes of an element.
+func (p *rdfXMLParser) context(t xml.StartElement, ctx xmlContext) xmlContext {
+	for _, a := range t.Attr {
+		if a.Name.Space != nsXML {
+			continue
+		}
+
+		switch a.Name.Local {
+		case "base":
+			ctx.base = resolveIRI(ctx.base, a.Value)
+		case "lang":
+			ctx.lang = a.Value
+		}
+	}
+	return ctx
+}
+
+// rdfAttr returns the value of an rdf: attribute. Unqualified names are
+// accepted as older documents use them.
+func rdfAttr(t xml.StartElement, name string) (string, bool) {
+	for _, a := range t.Attr {
+		if a.Name.Local == name && (a.Name.Space == nsRDF || a.Name.Space == "") {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+func elementIRI(n xml.Name) string {
+	return n.Space + n.Local
+}
+
+// nodeElements reads node elements until the end of the enclosing element.
+func (p *rdfXMLParser) nodeElements(ctx xmlContext) error {
+	for {
+		tok, err := p.d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if _, err := p.nodeElement(t, ctx); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// nodeElement reads the description of a resource and returns it.
+func (p *rdfXMLParser) nodeElement(t xml.StartElement, ctx xmlContext) (string, error) {
+	ctx = p.context(t, ctx)
+
+	var s string
+
+	if v, ok := rdfAttr(t, "about"); ok {
+		s = resolveIRI(ctx.base, v)
+	} else if v, ok := rdfAttr(t, "ID"); ok {
+		s = resolveIRI(ctx.base, "#"+v)
+	} else if v, ok := rdfAttr(t, "nodeID"); ok {
+		s = "_:" + v
+	} else {
+		s = p.blank()
+	}
+
+	if t.Name.Space != nsRDF || t.Name.Local != "Description" {
+		p.emit(&triple{S: s, P: iriType, O: elementIRI(t.Name)})
+	}
+
+	for _, a := range t.Attr {
+		if isSyntaxAttr(a.Name) {
+			continue
+		}
+
+		if a.Name.Space == nsRDF && a.Name.Local == "type" {
+			p.emit(&triple{S: s, P: iriType, O: resolveIRI(ctx.base, a.Value)})
+			continue
+		}
+
+		p.emit(&triple{S: s, P: elementIRI(a.Name), O: a.Value, Lit: true, Lang: ctx.lang})
+	}
+
+	for {
+		tok, err := p.d.Token()
+		if err != nil {
+			return "", err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if err := p.propertyElement(t, s, ctx); err != nil {
+				return "", err
+			}
+		case xml.EndElement:
+			return s, nil
+		}
+	}
+}
+
+// propertyElement reads a statement about s.
+func (p *rdfXMLParser) propertyElement(t xml.StartElement, s string, ctx xmlContext) error {
+	ctx = p.context(t, ctx)
+	pred := elementIRI(t.Name)
+
+	if v, ok := rdfAttr(t, "resource"); ok {
+		p.emit(&triple{S: s, P: pred, O: resolveIRI(ctx.base, v)})
+		return p.d.Skip()
+	}
+
+	if v, ok := rdfAttr(t, "nodeID"); ok {
+		p.emit(&triple{S: s, P: pred, O: "_:" + v})
+		return p.d.Skip()
+	}
+
+	parseType, _ := rdfAttr(t, "parseType")
+
+	switch parseType {
+	case "":
+	case "Resource":
+		o := p.blank()
+		p.emit(&triple{S: s, P: pred, O: o})
+
+		for {
+			tok, err := p.d.Token()
+			if err != nil {
+				return err
+			}
+
+			switch t := tok.(type) {
+			case xml.StartElement:
+				if err := p.propertyElement(t, o, ctx); err != nil {
+					return err
+				}
+			case xml.EndElement:
+				return nil
+			}
+		}
+	case "Collection":
+		// The list structure is not needed, but the members may describe
+		// named resources.
+		p.emit(&triple{S: s, P: pred, O: p.blank()})
+		return p.nodeElements(ctx)
+	default:
+		// XML literals are not used for any class field.
+		return p.d.Skip()
+	}
+
+	var (
+		text strings.Builder
+		obj  string
+	)
+
+	for {
+		tok, err := p.d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.CharData:
+			text.Write(t)
+
+		case xml.StartElement:
+			if obj, err = p.nodeElement(t, ctx); err != nil {
+				return err
+			}
+
+		case xml.EndElement:
+			if obj != "" {
+				p.emit(&triple{S: s, P: pred, O: obj})
+			} else {
+				p.emit(&triple{S: s, P: pred, O: text.String(), Lit: true, Lang: ctx.lang})
+			}
+			return nil
+		}
+	}
+}
+
+// isSyntaxAttr reports whether the attribute is part of the RDF/XML syntax
+// rather than a property. Unqualified attributes are never properties.
+func isSyntaxAttr(n xml.Name) bool {
+	switch n.Space {
+	case "", nsXML, "xmlns":
+		return true
+	case nsRDF:
+		switch n.Local {
+		case "about", "ID", "nodeID", "resource", "parseType", "datatype":
+			return true
+		}
+	}
+	return false
+}
+
+// resolveIRI resolves a possibly relative IRI against the base.
+func resolveIRI(base, ref string) string {
+	if base == "" {
+		return ref
+	}
+
+	b, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+
+	r, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+
+	if r.IsAbs() {
+		return ref
+	}
+
+	return b.ResolveReference(r).String()
+}
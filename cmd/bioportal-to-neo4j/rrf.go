@@ -0,0 +1,375 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// nsBioPortal is the namespace of the classes of UMLS sources in BioPortal.
+// Classes read from RRF files use the same IDs.
+const nsBioPortal = "http://purl.bioontology.org/ontology/"
+
+// Columns of the RRF files that are read.
+const (
+	// MRCONSO
+	consoCUI      = 0
+	consoTS       = 2
+	consoSTT      = 4
+	consoISPREF   = 6
+	consoAUI      = 7
+	consoSCUI     = 9
+	consoSAB      = 11
+	consoTTY      = 12
+	consoCODE     = 13
+	consoSTR      = 14
+	consoSUPPRESS = 16
+
+	// MRREL
+	relAUI1 = 1
+	relREL  = 3
+	relAUI2 = 5
+	relSAB  = 10
+
+	// MRSTY
+	styCUI = 0
+	styTUI = 1
+
+	// MRDEF
+	defAUI = 1
+	defSAB = 4
+	defDEF = 5
+
+	// MRSAT
+	satMETAUI = 3
+	satCODE   = 5
+	satATN    = 8
+	satSAB    = 9
+	satATV    = 10
+
+	// MRRANK
+	rankRANK = 0
+	rankSAB  = 1
+	rankTTY  = 2
+)
+
+// rrfClass accumulates the atoms of a source concept.
+type rrfClass struct {
+	c *class
+
+	// rank of the atom used as the label.
+	rank int
+
+	// atoms is the number of atoms and suppressed the number of those that
+	// are obsolete.
+	atoms      int
+	suppressed int
+}
+
+// rrfReader builds the classes of one UMLS source vocabulary from the RRF
+// files of a Metathesaurus release. A class is created for each code of the
+// source with its preferred name as the label, the names of its other atoms
+// as synonyms, its CUIs and their semantic types. PAR and CHD relations
+// between the source's atoms become subClassOf edges.
+type rrfReader struct {
+	dir   string
+	sab   string
+	props []propMapping
+
+	ranks map[string]int
+
+	order  []*rrfClass
+	byCode map[string]*rrfClass
+	byAUI  map[string]*rrfClass
+	byCUI  map[string][]*rrfClass
+}
+
+// newRRFReader reads the source vocabulary sab from the RRF files in dir,
+// or in the directory of the file dir names. MRDEF.RRF, MRRANK.RRF and
+// MRSAT.RRF are optional; the latter is only read if properties are mapped,
+// by attribute name.
+func newRRFReader(dir, sab string, props []propMapping) (classReader, error) {
+	if sab == "" {
+		return nil, errors.New("source abbreviation (SAB) required for RRF input")
+	}
+
+	if info, err := os.Stat(dir); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+
+	r := &rrfReader{
+		dir:    dir,
+		sab:    sab,
+		props:  props,
+		ranks:  make(map[string]int),
+		byCode: make(map[string]*rrfClass),
+		byAUI:  make(map[string]*rrfClass),
+		byCUI:  make(map[string][]*rrfClass),
+	}
+
+	files := []rrfFile{
+		{"MRRANK.RRF", false, r.readRank},
+		{"MRCONSO.RRF", true, r.readConso},
+		{"MRSTY.RRF", true, r.readSty},
+		{"MRDEF.RRF", false, r.readDef},
+		{"MRREL.RRF", true, r.readRel},
+	}
+
+	if len(props) > 0 {
+		files = append(files, rrfFile{"MRSAT.RRF", true, r.readSat})
+	}
+
+	for _, f := range files {
+		if err := r.scan(f); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(r.order) == 0 {
+		return nil, fmt.Errorf("no atoms found for source %s", sab)
+	}
+
+	var classes []*class
+
+	for _, rc := range r.order {
+		if rc.atoms > 0 && rc.suppressed == rc.atoms {
+			rc.c.Obsolete = "true"
+		}
+		classes = append(classes, rc.c)
+	}
+
+	return &sliceReader{classes: classes}, nil
+}
+
+// rrfFile is an RRF file and the function reading its rows.
+type rrfFile struct {
+	name     string
+	required bool
+	read     func([]string)
+}
+
+// scan calls read with the fields of each row of the file that mentions the
+// source. A gzipped copy of the file is used if it exists.
+func (r *rrfReader) scan(file rrfFile) error {
+	name := file.name
+	path := filepath.Join(r.dir, name)
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if _, gzErr := os.Stat(path + ".gz"); gzErr == nil {
+			path += ".gz"
+		} else if !file.required {
+			return nil
+		}
+	}
+
+	f, err := openInput(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	// MRSTY rows do not have a SAB column.
+	filter := name != "MRSTY.RRF"
+	sab := "|" + r.sab + "|"
+
+	for sc.Scan() {
+		line := sc.Text()
+		if filter && !strings.Contains(line, sab) {
+			continue
+		}
+		file.read(strings.Split(line, "|"))
+	}
+
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("%s: %s", name, err)
+	}
+
+	return nil
+}
+
+func (r *rrfReader) readRank(row []string) {
+	if len(row) <= rankTTY || row[rankSAB] != r.sab {
+		return
+	}
+
+	if n, err := strconv.Atoi(row[rankRANK]); err == nil {
+		r.ranks[row[rankTTY]] = n
+	}
+}
+
+// rank returns the precedence of an atom as its label. MRRANK is used if it
+// was read, otherwise preferred terms are favored.
+func (r *rrfReader) rank(row []string) int {
+	if n, ok := r.ranks[row[consoTTY]]; ok {
+		return n
+	}
+
+	n := 0
+	if row[consoTTY] == "PT" {
+		n += 4
+	}
+	if row[consoTS] == "P" && row[consoSTT] == "PF" {
+		n += 2
+	}
+	if row[consoISPREF] == "Y" {
+		n++
+	}
+	return n
+}
+
+func (r *rrfReader) readConso(row []string) {
+	if len(row) <= consoSUPPRESS || row[consoSAB] != r.sab {
+		return
+	}
+
+	code := row[consoCODE]
+	if code == "" || code == "NOCODE" {
+		code = row[consoSCUI]
+	}
+	if code == "" {
+		code = row[consoAUI]
+	}
+
+	rc, ok := r.byCode[code]
+	if !ok {
+		id := nsBioPortal + r.sab + "/" + code
+
+		rc = &rrfClass{
+			c: &class{
+				ID:       id,
+				Code:     codeFromID(id),
+				Obsolete: "false",
+				Props:    make([]string, len(r.props)),
+			},
+			rank: -1,
+		}
+
+		r.byCode[code] = rc
+		r.order = append(r.order, rc)
+	}
+
+	r.byAUI[row[consoAUI]] = rc
+
+	c := rc.c
+
+	if cui := row[consoCUI]; !contains(c.CUI, cui) {
+		c.CUI = append(c.CUI, cui)
+		r.byCUI[cui] = append(r.byCUI[cui], rc)
+	}
+
+	rc.atoms++
+
+	// Obsolete atoms are only counted.
+	if row[consoSUPPRESS] == "O" {
+		rc.suppressed++
+		if c.Label == "" {
+			c.Label = row[consoSTR]
+		}
+		return
+	}
+
+	str := row[consoSTR]
+
+	if n := r.rank(row); n > rc.rank {
+		if c.Label != "" && rc.rank >= 0 {
+			c.Synonyms = appendUnique(c.Synonyms, c.Label)
+		}
+		c.Label = str
+		rc.rank = n
+	} else {
+		c.Synonyms = appendUnique(c.Synonyms, str)
+	}
+
+	// Keep the label out of the synonyms.
+	if i := index(c.Synonyms, c.Label); i >= 0 {
+		c.Synonyms = append(c.Synonyms[:i], c.Synonyms[i+1:]...)
+	}
+}
+
+func (r *rrfReader) readSty(row []string) {
+	if len(row) <= styTUI {
+		return
+	}
+
+	for _, rc := range r.byCUI[row[styCUI]] {
+		rc.c.SemanticTypes = appendUnique(rc.c.SemanticTypes, row[styTUI])
+	}
+}
+
+func (r *rrfReader) readDef(row []string) {
+	if len(row) <= defDEF || row[defSAB] != r.sab {
+		return
+	}
+
+	if rc, ok := r.byAUI[row[defAUI]]; ok {
+		rc.c.Definitions = appendUnique(rc.c.Definitions, row[defDEF])
+	}
+}
+
+// readRel adds subClassOf edges. REL is the relationship of the second atom
+// to the first, so PAR means AUI2 is a parent of AUI1 and CHD that AUI2 is a
+// child of AUI1.
+func (r *rrfReader) readRel(row []string) {
+	if len(row) <= relSAB || row[relSAB] != r.sab {
+		return
+	}
+
+	a1, ok1 := r.byAUI[row[relAUI1]]
+	a2, ok2 := r.byAUI[row[relAUI2]]
+	if !ok1 || !ok2 || a1 == a2 {
+		return
+	}
+
+	switch row[relREL] {
+	case "PAR":
+		a1.c.Parents = appendUnique(a1.c.Parents, a2.c.ID)
+	case "CHD":
+		a2.c.Parents = appendUnique(a2.c.Parents, a1.c.ID)
+	}
+}
+
+func (r *rrfReader) readSat(row []string) {
+	if len(row) <= satATV || row[satSAB] != r.sab {
+		return
+	}
+
+	rc, ok := r.byCode[row[satCODE]]
+	if !ok {
+		if rc, ok = r.byAUI[row[satMETAUI]]; !ok {
+			return
+		}
+	}
+
+	for i, p := range r.props {
+		if p.Column != row[satATN] {
+			continue
+		}
+
+		if rc.c.Props[i] != "" {
+			rc.c.Props[i] += "|"
+		}
+		rc.c.Props[i] += row[satATV]
+	}
+}
+
+func index(s []string, v string) int {
+	for i, x := range s {
+		if x == v {
+			return i
+		}
+	}
+	return -1
+}
+
+func contains(s []string, v string) bool {
+	return index(s, v) >= 0
+}
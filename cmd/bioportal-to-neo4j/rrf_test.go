@@ -0,0 +1,129 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var testRRF = map[string]string{
+	"MRCONSO.RRF": `C0000001|ENG|P|L1|PF|S1|Y|A1||||ICD10CM|HT|A00-B99|Certain infectious and parasitic diseases|0|N||
+C0000002|ENG|P|L2|PF|S2|Y|A2||||ICD10CM|PT|A00|Cholera|0|N||
+C0000002|ENG|S|L3|PF|S3|N|A3||||ICD10CM|AB|A00|Cholera (abbr)|0|N||
+C0000002|ENG|P|L2|PF|S2|Y|A4||||MSH|MH|D002771|Cholera|0|N||
+C0000003|ENG|P|L4|PF|S4|Y|A5||||ICD10CM|PT|A01|Old term|0|O||
+`,
+	"MRREL.RRF": `C0000002|A2|AUI|PAR|C0000001|A1|AUI||R1||ICD10CM|ICD10CM|||N||
+C0000001|A1|AUI|CHD|C0000002|A2|AUI||R2||ICD10CM|ICD10CM|||N||
+C0000001|A1|AUI|CHD|C0000003|A5|AUI||R3||ICD10CM|ICD10CM|||N||
+C0000002|A4|AUI|PAR|C0000001|A9|AUI||R4||MSH|MSH|||N||
+`,
+	"MRSTY.RRF": `C0000002|T047|B2.2.1.2.1|Disease or Syndrome|AT1||
+C0000003|T047|B2.2.1.2.1|Disease or Syndrome|AT2||
+`,
+	"MRDEF.RRF": `C0000002|A2|AT3||ICD10CM|An acute diarrheal disease.|N||
+`,
+}
+
+func writeRRF(t *testing.T, dir string, files map[string]string) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRRFReader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rrf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeRRF(t, dir, testRRF)
+
+	r, err := newRRFReader(dir, "ICD10CM", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	classes, err := readAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(classes) != 3 {
+		t.Fatalf("expected 3 classes, got %d", len(classes))
+	}
+
+	exp := &class{
+		ID:            "http://purl.bioontology.org/ontology/ICD10CM/A00",
+		Label:         "Cholera",
+		Code:          "A00",
+		Synonyms:      []string{"Cholera (abbr)"},
+		Definitions:   []string{"An acute diarrheal disease."},
+		Obsolete:      "false",
+		CUI:           []string{"C0000002"},
+		SemanticTypes: []string{"T047"},
+		Parents:       []string{"http://purl.bioontology.org/ontology/ICD10CM/A00-B99"},
+		Props:         []string{},
+	}
+
+	if !reflect.DeepEqual(classes[1], exp) {
+		t.Errorf("expected %+v, got %+v", exp, classes[1])
+	}
+
+	old := classes[2]
+	if old.Obsolete != "true" || !reflect.DeepEqual(old.Parents, exp.Parents) {
+		t.Errorf("unexpected class %+v", old)
+	}
+
+	if _, err := newRRFReader(dir, "SNOMEDCT_US", nil); err == nil {
+		t.Error("expected error for a source without atoms")
+	}
+}
+
+func TestUpdateRRF(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rrf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The previous release names A00 differently.
+	prev := make(map[string]string)
+	for name, data := range testRRF {
+		prev[name] = data
+	}
+	prev["MRCONSO.RRF"] = strings.Replace(prev["MRCONSO.RRF"], "|Cholera|", "|Cholera, unspecified|", 1)
+
+	writeRRF(t, filepath.Join(dir, "prev"), prev)
+	writeRRF(t, filepath.Join(dir, "next"), testRRF)
+
+	v := &vocab{
+		ID:     "icd10cm",
+		Source: filepath.Join(dir, "next"),
+		Format: formatRRF,
+		SAB:    "ICD10CM",
+	}
+
+	if err := update(v, filepath.Join(dir, "prev"), &options{outDir: dir}); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "icd10cm_diff_relabeled.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(b), "ICD10CM/A00,\"Cholera, unspecified\",Cholera") {
+		t.Errorf("unexpected relabeled classes:\n%s", b)
+	}
+}
@@ -0,0 +1,694 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// turtleParser emits the triples of a Turtle document.
+type turtleParser struct {
+	r    *bufio.Reader
+	emit func(*triple)
+
+	// ahead holds runes that were read but not consumed.
+	ahead []rune
+	line  int
+
+	base     string
+	prefixes map[string]string
+	blanks   int
+}
+
+// turtleTerm is a parsed subject or object.
+type turtleTerm struct {
+	value string
+	lit   bool
+	lang  string
+}
+
+func parseTurtle(r io.Reader, emit func(*triple)) error {
+	p := &turtleParser{
+		r:        bufio.NewReader(r),
+		emit:     emit,
+		line:     1,
+		prefixes: make(map[string]string),
+	}
+
+	for {
+		if err := p.skipSpace(); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if err := p.statement(); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("turtle: line %d: %s", p.line, err)
+		}
+	}
+}
+
+func (p *turtleParser) peek() (rune, error) {
+	if len(p.ahead) == 0 {
+		c, _, err := p.r.ReadRune()
+		if err != nil {
+			return 0, err
+		}
+		p.ahead = append(p.ahead, c)
+	}
+	return p.ahead[len(p.ahead)-1], nil
+}
+
+func (p *turtleParser) next() (rune, error) {
+	c, err := p.peek()
+	if err != nil {
+		return 0, err
+	}
+
+	p.ahead = p.ahead[:len(p.ahead)-1]
+	if c == '\n' {
+		p.line++
+	}
+	return c, nil
+}
+
+func (p *turtleParser) unread(c rune) {
+	if c == '\n' {
+		p.line--
+	}
+	p.ahead = append(p.ahead, c)
+}
+
+// skipSpace skips whitespace and comments.
+func (p *turtleParser) skipSpace() error {
+	for {
+		c, err := p.peek()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case c == '#':
+			for c != '\n' {
+				if c, err = p.next(); err != nil {
+					return err
+				}
+			}
+		case unicode.IsSpace(c):
+			p.next()
+		default:
+			return nil
+		}
+	}
+}
+
+func (p *turtleParser) expect(want rune) error {
+	if err := p.skipSpace(); err != nil {
+		return err
+	}
+
+	c, err := p.next()
+	if err != nil {
+		return err
+	}
+
+	if c != want {
+		return fmt.Errorf("expected %q, got %q", want, c)
+	}
+	return nil
+}
+
+func (p *turtleParser) blank() string {
+	p.blanks++
+	return "_:#" + strconv.Itoa(p.blanks)
+}
+
+func (p *turtleParser) statement() error {
+	c, err := p.peek()
+	if err != nil {
+		return err
+	}
+
+	// @prefix and @base directives end with a dot, the SPARQL style
+	// PREFIX and BASE directives do not.
+	if c == '@' {
+		p.next()
+
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+
+		if err := p.directive(name); err != nil {
+			return err
+		}
+
+		return p.expect('.')
+	}
+
+	var subj string
+
+	if isNameStart(c) {
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+
+		switch strings.ToLower(name) {
+		case "prefix", "base":
+			return p.directive(strings.ToLower(name))
+		}
+
+		if subj, err = p.expand(name); err != nil {
+			return err
+		}
+	} else {
+		t, err := p.term()
+		if err != nil {
+			return err
+		}
+
+		if t.lit {
+			return errors.New("literal used as subject")
+		}
+
+		subj = t.value
+
+		// A blank node property list may stand on its own.
+		if c == '[' {
+			if err := p.skipSpace(); err != nil {
+				return err
+			}
+
+			if c, err := p.peek(); err != nil {
+				return err
+			} else if c == '.' {
+				p.next()
+				return nil
+			}
+		}
+	}
+
+	if err := p.predicateObjects(subj); err != nil {
+		return err
+	}
+
+	return p.expect('.')
+}
+
+func (p *turtleParser) directive(name string) error {
+	if err := p.skipSpace(); err != nil {
+		return err
+	}
+
+	switch name {
+	case "prefix":
+		prefix, err := p.name()
+		if err != nil {
+			return err
+		}
+
+		if !strings.HasSuffix(prefix, ":") {
+			return fmt.Errorf("invalid prefix %q", prefix)
+		}
+
+		if err := p.skipSpace(); err != nil {
+			return err
+		}
+
+		iri, err := p.iri()
+		if err != nil {
+			return err
+		}
+
+		p.prefixes[strings.TrimSuffix(prefix, ":")] = iri
+
+	case "base":
+		iri, err := p.iri()
+		if err != nil {
+			return err
+		}
+
+		p.base = iri
+
+	default:
+		return fmt.Errorf("unknown directive %q", name)
+	}
+
+	return nil
+}
+
+// predicateObjects reads a predicate object list about subj.
+func (p *turtleParser) predicateObjects(subj string) error {
+	for {
+		if err := p.skipSpace(); err != nil {
+			return err
+		}
+
+		pred, err := p.verb()
+		if err != nil {
+			return err
+		}
+
+		for {
+			if err := p.skipSpace(); err != nil {
+				return err
+			}
+
+			obj, err := p.term()
+			if err != nil {
+				return err
+			}
+
+			p.emit(&triple{S: subj, P: pred, O: obj.value, Lit: obj.lit, Lang: obj.lang})
+
+			if err := p.skipSpace(); err != nil {
+				return err
+			}
+
+			if c, _ := p.peek(); c != ',' {
+				break
+			}
+			p.next()
+		}
+
+		if c, _ := p.peek(); c != ';' {
+			return nil
+		}
+
+		// Any number of semicolons may separate, or trail, the predicates.
+		for {
+			p.next()
+			if err := p.skipSpace(); err != nil {
+				return err
+			}
+
+			c, err := p.peek()
+			if err != nil {
+				return err
+			}
+
+			if c == '.' || c == ']' {
+				return nil
+			}
+			if c != ';' {
+				break
+			}
+		}
+	}
+}
+
+func (p *turtleParser) verb() (string, error) {
+	c, err := p.peek()
+	if err != nil {
+		return "", err
+	}
+
+	if c == '<' {
+		return p.iri()
+	}
+
+	name, err := p.name()
+	if err != nil {
+		return "", err
+	}
+
+	if name == "a" {
+		return iriType, nil
+	}
+
+	return p.expand(name)
+}
+
+// term reads an IRI, blank node, collection or literal.
+func (p *turtleParser) term() (*turtleTerm, error) {
+	c, err := p.peek()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case c == '<':
+		iri, err := p.iri()
+		return &turtleTerm{value: iri}, err
+
+	case c == '"' || c == '\'':
+		return p.literal()
+
+	case c == '[':
+		p.next()
+
+		b := p.blank()
+
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+
+		if c, err := p.peek(); err != nil {
+			return nil, err
+		} else if c != ']' {
+			if err := p.predicateObjects(b); err != nil {
+				return nil, err
+			}
+		}
+
+		return &turtleTerm{value: b}, p.expect(']')
+
+	case c == '(':
+		p.next()
+		return p.collection()
+
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		var b strings.Builder
+		for {
+			c, err := p.peek()
+			if err != nil && err != io.EOF {
+				return nil, err
+			}
+			if err == io.EOF || !strings.ContainsRune("+-.eE0123456789", c) {
+				break
+			}
+			b.WriteRune(c)
+			p.next()
+		}
+
+		// A trailing dot ends the statement.
+		num := b.String()
+		if strings.HasSuffix(num, ".") {
+			num = num[:len(num)-1]
+			p.unread('.')
+		}
+
+		return &turtleTerm{value: num, lit: true}, nil
+
+	case isNameStart(c):
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+
+		if name == "true" || name == "false" {
+			return &turtleTerm{value: name, lit: true}, nil
+		}
+
+		iri, err := p.expand(name)
+		return &turtleTerm{value: iri}, err
+	}
+
+	return nil, fmt.Errorf("unexpected %q", c)
+}
+
+// collection reads the members of an RDF list and returns its head.
+func (p *turtleParser) collection() (*turtleTerm, error) {
+	head := nsRDF + "nil"
+	prev := ""
+
+	for {
+		if err := p.skipSpace(); err != nil {
+			return nil, err
+		}
+
+		if c, err := p.peek(); err != nil {
+			return nil, err
+		} else if c == ')' {
+			p.next()
+			break
+		}
+
+		t, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+
+		node := p.blank()
+		if prev == "" {
+			head = node
+		} else {
+			p.emit(&triple{S: prev, P: nsRDF + "rest", O: node})
+		}
+
+		p.emit(&triple{S: node, P: nsRDF + "first", O: t.value, Lit: t.lit, Lang: t.lang})
+		prev = node
+	}
+
+	if prev != "" {
+		p.emit(&triple{S: prev, P: nsRDF + "rest", O: nsRDF + "nil"})
+	}
+
+	return &turtleTerm{value: head}, nil
+}
+
+// iri reads an IRI reference and resolves it against the base.
+func (p *turtleParser) iri() (string, error) {
+	if c, err := p.next(); err != nil {
+		return "", err
+	} else if c != '<' {
+		return "", fmt.Errorf("expected IRI, got %q", c)
+	}
+
+	var b strings.Builder
+
+	for {
+		c, err := p.next()
+		if err != nil {
+			return "", err
+		}
+
+		switch c {
+		case '>':
+			return resolveIRI(p.base, b.String()), nil
+		case '\\':
+			r, err := p.escape()
+			if err != nil {
+				return "", err
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune(c)
+		}
+	}
+}
+
+// literal reads a quoted string and its language tag or datatype.
+func (p *turtleParser) literal() (*turtleTerm, error) {
+	q, _ := p.next()
+
+	// Three quotes start a long string, two an empty one.
+	long := false
+	if c, err := p.peek(); err == nil && c == q {
+		p.next()
+		if c, err := p.peek(); err == nil && c == q {
+			p.next()
+			long = true
+		} else {
+			return p.suffix("")
+		}
+	}
+
+	var b strings.Builder
+
+	for {
+		c, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+
+		if c == '\\' {
+			r, err := p.escape()
+			if err != nil {
+				return nil, err
+			}
+			b.WriteRune(r)
+			continue
+		}
+
+		if c != q {
+			b.WriteRune(c)
+			continue
+		}
+
+		if !long {
+			break
+		}
+
+		// Count the closing quotes of a long string.
+		n := 1
+		for n < 3 {
+			c, err := p.peek()
+			if err != nil || c != q {
+				break
+			}
+			p.next()
+			n++
+		}
+
+		if n == 3 {
+			break
+		}
+
+		for ; n > 0; n-- {
+			b.WriteRune(q)
+		}
+	}
+
+	return p.suffix(b.String())
+}
+
+// suffix reads the optional language tag or datatype of a literal.
+func (p *turtleParser) suffix(s string) (*turtleTerm, error) {
+	t := &turtleTerm{value: s, lit: true}
+
+	c, err := p.peek()
+	if err == io.EOF {
+		return t, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	switch c {
+	case '@':
+		p.next()
+
+		var b strings.Builder
+		for {
+			c, err := p.peek()
+			if err != nil || !(c == '-' || unicode.IsLetter(c) || unicode.IsDigit(c)) {
+				break
+			}
+			b.WriteRune(c)
+			p.next()
+		}
+		t.lang = b.String()
+
+	case '^':
+		p.next()
+		if err := p.expect('^'); err != nil {
+			return nil, err
+		}
+
+		// The datatype is not needed.
+		if _, err := p.verb(); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+func (p *turtleParser) escape() (rune, error) {
+	c, err := p.next()
+	if err != nil {
+		return 0, err
+	}
+
+	switch c {
+	case 't':
+		return '\t', nil
+	case 'b':
+		return '\b', nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 'f':
+		return '\f', nil
+	case 'u', 'U':
+		n := 4
+		if c == 'U' {
+			n = 8
+		}
+
+		hex := make([]rune, n)
+		for i := range hex {
+			if hex[i], err = p.next(); err != nil {
+				return 0, err
+			}
+		}
+
+		v, err := strconv.ParseUint(string(hex), 16, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid escape \\%c%s", c, string(hex))
+		}
+		return rune(v), nil
+	}
+
+	// Quotes, backslashes and the reserved characters of local names
+	// escape themselves.
+	return c, nil
+}
+
+func isNameStart(c rune) bool {
+	return c == '_' || c == ':' || unicode.IsLetter(c)
+}
+
+func isNameChar(c rune) bool {
+	return isNameStart(c) || unicode.IsDigit(c) || strings.ContainsRune("-.%\\", c)
+}
+
+// name reads a prefixed name, blank node label or keyword. A trailing dot
+// is left to end the statement.
+func (p *turtleParser) name() (string, error) {
+	var b strings.Builder
+
+	for {
+		c, err := p.peek()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return "", err
+		}
+
+		if !isNameChar(c) {
+			break
+		}
+		p.next()
+
+		if c == '\\' {
+			if c, err = p.next(); err != nil {
+				return "", err
+			}
+		}
+
+		b.WriteRune(c)
+	}
+
+	s := b.String()
+	for strings.HasSuffix(s, ".") {
+		s = s[:len(s)-1]
+		p.unread('.')
+	}
+
+	if s == "" {
+		return "", errors.New("expected name")
+	}
+
+	return s, nil
+}
+
+// expand resolves a prefixed name or blank node label.
+func (p *turtleParser) expand(name string) (string, error) {
+	i := strings.Index(name, ":")
+	if i < 0 {
+		return "", fmt.Errorf("unexpected %q", name)
+	}
+
+	prefix, local := name[:i], name[i+1:]
+
+	if prefix == "_" {
+		return name, nil
+	}
+
+	ns, ok := p.prefixes[prefix]
+	if !ok {
+		return "", fmt.Errorf("undefined prefix %q", prefix)
+	}
+
+	return ns + local, nil
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Validation modes.
+const (
+	validateOff     = "off"
+	validateLenient = "lenient"
+	validateStrict  = "strict"
+)
+
+// Kinds of validation issues.
+const (
+	issueDuplicateID      = "duplicate-id"
+	issueDuplicateCode    = "duplicate-code"
+	issueEmptyLabel       = "empty-label"
+	issueDanglingParent   = "dangling-parent"
+	issueDanglingRelation = "dangling-relation"
+	issueCycle            = "cycle"
+)
+
+type issue struct {
+	Kind    string
+	ClassID string
+	Detail  string
+
+	// Dropped is true if the row or edge was removed in lenient mode.
+	Dropped bool
+}
+
+type report struct {
+	Issues []*issue
+}
+
+func (r *report) add(i *issue) {
+	r.Issues = append(r.Issues, i)
+}
+
+// Summary writes the number of issues of each kind.
+func (r *report) Summary(w io.Writer) {
+	counts := make(map[string]int)
+	dropped := make(map[string]int)
+
+	for _, i := range r.Issues {
+		counts[i.Kind]++
+		if i.Dropped {
+			dropped[i.Kind]++
+		}
+	}
+
+	var kinds []string
+	for k := range counts {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+
+	for _, k := range kinds {
+		fmt.Fprintf(w, "%s: %d", k, counts[k])
+		if n := dropped[k]; n > 0 {
+			fmt.Fprintf(w, " (%d dropped)", n)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// WriteCSV writes every issue as a CSV row.
+func (r *report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"kind", "class", "detail", "dropped"})
+
+	for _, i := range r.Issues {
+		cw.Write([]string{i.Kind, i.ClassID, i.Detail, fmt.Sprint(i.Dropped)})
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// validate checks the classes for duplicate IDs and codes, empty labels,
+// parents and relation targets that do not exist and subclass cycles. If
+// lenient is true, rows with duplicate IDs, dangling edges and edges closing
+// a cycle are removed so the output can be imported. The remaining classes are returned.
+func validate(classes []*class, lenient bool) ([]*class, *report) {
+	r := &report{}
+
+	// Duplicate IDs. The first occurrence is kept.
+	byID := make(map[string]*class, len(classes))
+	uniq := classes[:0:0]
+
+	for _, c := range classes {
+		if _, ok := byID[c.ID]; ok {
+			r.add(&issue{
+				Kind:    issueDuplicateID,
+				ClassID: c.ID,
+				Detail:  "class ID appears more than once",
+				Dropped: lenient,
+			})
+			if lenient {
+				continue
+			}
+		} else {
+			byID[c.ID] = c
+		}
+		uniq = append(uniq, c)
+	}
+
+	classes = uniq
+
+	codes := make(map[string]string, len(classes))
+
+	for _, c := range classes {
+		if other, ok := codes[c.Code]; ok && other != c.ID {
+			r.add(&issue{
+				Kind:    issueDuplicateCode,
+				ClassID: c.ID,
+				Detail:  fmt.Sprintf("code %q also used by %s", c.Code, other),
+			})
+		} else {
+			codes[c.Code] = c.ID
+		}
+
+		if c.Label == "" {
+			r.add(&issue{
+				Kind:    issueEmptyLabel,
+				ClassID: c.ID,
+				Detail:  "preferred label is empty",
+			})
+		}
+
+		var parents []string
+
+		for _, pid := range c.Parents {
+			if _, ok := byID[pid]; !ok {
+				r.add(&issue{
+					Kind:    issueDanglingParent,
+					ClassID: c.ID,
+					Detail:  fmt.Sprintf("parent %s does not exist", pid),
+					Dropped: lenient,
+				})
+				if lenient {
+					continue
+				}
+			}
+			parents = append(parents, pid)
+		}
+
+		c.Parents = parents
+
+		var rels []relation
+
+		for _, rel := range c.Relations {
+			if _, ok := byID[rel.Target]; !ok {
+				r.add(&issue{
+					Kind:    issueDanglingRelation,
+					ClassID: c.ID,
+					Detail:  fmt.Sprintf("%s target %s does not exist", rel.Type, rel.Target),
+					Dropped: lenient,
+				})
+				if lenient {
+					continue
+				}
+			}
+			rels = append(rels, rel)
+		}
+
+		c.Relations = rels
+	}
+
+	findCycles(classes, byID, r, lenient)
+
+	return classes, r
+}
+
+// findCycles walks the subclass edges depth-first and reports each edge that
+// leads back to a class on the current path.
+func findCycles(classes []*class, byID map[string]*class, r *report, lenient bool) {
+	const (
+		unvisited = iota
+		active
+		done
+	)
+
+	state := make(map[string]int, len(classes))
+
+	var visit func(c *class)
+	visit = func(c *class) {
+		state[c.ID] = active
+
+		parents := c.Parents[:0:0]
+
+		for _, pid := range c.Parents {
+			p, ok := byID[pid]
+			if !ok {
+				parents = append(parents, pid)
+				continue
+			}
+
+			switch state[pid] {
+			case active:
+				r.add(&issue{
+					Kind:    issueCycle,
+					ClassID: c.ID,
+					Detail:  fmt.Sprintf("subClassOf %s closes a cycle", pid),
+					Dropped: lenient,
+				})
+				if lenient {
+					continue
+				}
+
+			case unvisited:
+				visit(p)
+			}
+
+			parents = append(parents, pid)
+		}
+
+		c.Parents = parents
+		state[c.ID] = done
+	}
+
+	for _, c := range classes {
+		if state[c.ID] == unvisited {
+			visit(c)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	newClasses := func() []*class {
+		return []*class{
+			{ID: "a", Code: "a", Label: "A"},
+			{ID: "b", Code: "b", Label: "B", Parents: []string{"a", "x"}},
+			{ID: "c", Code: "c", Label: "", Parents: []string{"b", "d"}},
+			{ID: "d", Code: "d", Label: "D", Parents: []string{"c"}},
+			{ID: "b", Code: "b", Label: "B2"},
+		}
+	}
+
+	counts := func(r *report) map[string]int {
+		m := make(map[string]int)
+		for _, i := range r.Issues {
+			m[i.Kind]++
+		}
+		return m
+	}
+
+	exp := map[string]int{
+		issueDuplicateID:    1,
+		issueEmptyLabel:     1,
+		issueDanglingParent: 1,
+		issueCycle:          1,
+	}
+
+	classes, r := validate(newClasses(), true)
+
+	got := counts(r)
+	for k, n := range exp {
+		if got[k] != n {
+			t.Errorf("%s: expected %d, got %d", k, n, got[k])
+		}
+	}
+
+	if len(classes) != 4 {
+		t.Fatalf("expected duplicate to be dropped, got %d classes", len(classes))
+	}
+
+	if len(classes[1].Parents) != 1 {
+		t.Errorf("expected dangling parent to be dropped, got %v", classes[1].Parents)
+	}
+
+	// Exactly one edge of the c <-> d cycle is removed.
+	if n := len(classes[2].Parents) + len(classes[3].Parents); n != 2 {
+		t.Errorf("expected one cycle edge to be dropped, got %d edges", n)
+	}
+
+	classes, _ = validate(newClasses(), false)
+
+	if len(classes) != 5 || len(classes[1].Parents) != 2 {
+		t.Error("strict mode should not modify classes")
+	}
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var vocabHeader = []string{
+	"id:ID(Vocabulary)",
+	"label",
+	"submissionId:int",
+	"version",
+	"released",
+}
+
+var classHeader = []string{
+	"id:ID(Class)",
+	"label",
+	"code",
+	"synonyms:string[]",
+	"definitions:string[]",
+	"obsolete:boolean",
+	"cui:string[]",
+	"semanticTypes:string[]",
+}
+
+var subClassOfRelHeader = []string{
+	":START_ID(Class)",
+	":TYPE",
+	":END_ID(Class)",
+}
+
+var relationHeader = []string{
+	":START_ID(Class)",
+	":TYPE",
+	":END_ID(Class)",
+}
+
+var classOfRelHeader = []string{
+	":START_ID(Class)",
+	":TYPE",
+	":END_ID(Vocabulary)",
+}
+
+// classWriter is the destination of the classes read from the input.
+type classWriter interface {
+	Write(*class) error
+	Close() error
+}
+
+// csvFile is a CSV writer over a file that flushes and closes both.
+// Files named with a .gz extension are gzipped.
+type csvFile struct {
+	*csv.Writer
+	gz *gzip.Writer
+	f  *os.File
+}
+
+func createCSV(name string, header []string) (*csvFile, error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &csvFile{f: f}
+
+	if strings.HasSuffix(name, ".gz") {
+		w.gz = gzip.NewWriter(f)
+		w.Writer = csv.NewWriter(w.gz)
+	} else {
+		w.Writer = csv.NewWriter(f)
+	}
+
+	if err := w.Write(header); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *csvFile) Close() error {
+	w.Flush()
+	err := w.Error()
+
+	if w.gz != nil {
+		if cerr := w.gz.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+// csvWriter writes a vocabulary as the set of node and relationship files
+// consumed by neo4j-import.
+type csvWriter struct {
+	vocabID  string
+	dir      string
+	compress bool
+
+	classes    *csvFile
+	classOf    *csvFile
+	subClassOf *csvFile
+	relations  *csvFile
+
+	// written maps the IDs of the classes written by the writers sharing it
+	// to the last vocabulary related to them.
+	written map[string]string
+}
+
+// newCSVWriter creates the output files for the vocabulary in dir, gzipped
+// if compress is true. Additional class properties are appended to the
+// class node header. Writers loaded by the same neo4j-import run share
+// written, so a class in several vocabularies is written once.
+func newCSVWriter(dir string, v *vocab, compress bool, written map[string]string) (*csvWriter, error) {
+	w := &csvWriter{
+		vocabID:  v.ID,
+		dir:      dir,
+		compress: compress,
+		written:  written,
+	}
+
+	vf, err := createCSV(w.path("nodes_vocabulary"), vocabHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	row := []string{v.ID, v.Label, "", "", ""}
+	if s := v.Submission; s != nil {
+		row[2] = strconv.Itoa(s.ID)
+		row[3] = s.Version
+		row[4] = s.Released
+	}
+
+	if err := vf.Write(row); err != nil {
+		vf.Close()
+		return nil, err
+	}
+
+	if err := vf.Close(); err != nil {
+		return nil, err
+	}
+
+	header := append([]string{}, classHeader...)
+	for _, p := range v.Props {
+		header = append(header, p.Property)
+	}
+
+	if w.classes, err = createCSV(w.path("nodes_class"), header); err != nil {
+		return nil, err
+	}
+
+	if w.classOf, err = createCSV(w.path("rels_classof"), classOfRelHeader); err != nil {
+		w.Close()
+		return nil, err
+	}
+
+	if w.subClassOf, err = createCSV(w.path("rels_subclassof"), subClassOfRelHeader); err != nil {
+		w.Close()
+		return nil, err
+	}
+
+	if w.relations, err = createCSV(w.path("rels_relationship"), relationHeader); err != nil {
+		w.Close()
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (w *csvWriter) Write(c *class) error {
+	if w.written != nil {
+		if vocabID, ok := w.written[c.ID]; ok {
+			// The node and its edges were written by an earlier
+			// vocabulary, so it is only related to this one.
+			if vocabID == w.vocabID {
+				return nil
+			}
+			w.written[c.ID] = w.vocabID
+			return w.classOf.Write([]string{c.ID, "classOf", w.vocabID})
+		}
+		w.written[c.ID] = w.vocabID
+	}
+
+	row := []string{
+		c.ID,
+		c.Label,
+		c.Code,
+		strings.Join(c.Synonyms, "|"),
+		strings.Join(c.Definitions, "|"),
+		c.Obsolete,
+		strings.Join(c.CUI, "|"),
+		strings.Join(c.SemanticTypes, "|"),
+	}
+	row = append(row, c.Props...)
+
+	if err := w.classes.Write(row); err != nil {
+		return err
+	}
+
+	if err := w.classOf.Write([]string{c.ID, "classOf", w.vocabID}); err != nil {
+		return err
+	}
+
+	for _, pid := range c.Parents {
+		if err := w.subClassOf.Write([]string{c.ID, "subClassOf", pid}); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range c.Relations {
+		if err := w.relations.Write([]string{c.ID, r.Type, r.Target}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *csvWriter) Close() error {
+	var err error
+
+	for _, f := range []*csvFile{w.classes, w.classOf, w.subClassOf, w.relations} {
+		if f == nil {
+			continue
+		}
+		if cerr := f.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// path returns the path of one of the vocabulary's output files.
+func (w *csvWriter) path(name string) string {
+	ext := ".csv"
+	if w.compress {
+		ext += ".gz"
+	}
+	return filepath.Join(w.dir, fmt.Sprintf("%s_%s%s", w.vocabID, name, ext))
+}
+
+// importScript returns a neo4j-import command that loads the files of all
+// the vocabularies in a single run.
+func importScript(ws []*csvWriter) string {
+	var (
+		vocabs  []string
+		classes []string
+		rels    []string
+	)
+
+	for _, w := range ws {
+		vocabs = append(vocabs, w.path("nodes_vocabulary"))
+		classes = append(classes, w.path("nodes_class"))
+		rels = append(rels, w.path("rels_subclassof"), w.path("rels_classof"), w.path("rels_relationship"))
+	}
+
+	var b strings.Builder
+
+	b.WriteString(`./neo4j/bin/neo4j-import \
+	--into ./neo4j/data/databases/graph.db \
+	--delimiter ',' \
+	--array-delimiter '|' \
+	--quote '"' \
+`)
+
+	for _, p := range vocabs {
+		fmt.Fprintf(&b, "\t--nodes:Vocabulary %q \\\n", p)
+	}
+	for _, p := range classes {
+		fmt.Fprintf(&b, "\t--nodes:Class %q \\\n", p)
+	}
+	for i, p := range rels {
+		fmt.Fprintf(&b, "\t--relationships %q", p)
+		if i < len(rels)-1 {
+			b.WriteString(" \\")
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
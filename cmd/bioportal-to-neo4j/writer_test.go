@@ -0,0 +1,96 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImportScript(t *testing.T) {
+	a := &csvWriter{vocabID: "a", dir: "import"}
+	b := &csvWriter{vocabID: "b", dir: "import"}
+
+	s := importScript([]*csvWriter{a, b})
+
+	for _, p := range []string{"import/a_nodes_class.csv", "import/b_nodes_class.csv", "import/b_rels_classof.csv"} {
+		if !strings.Contains(s, p) {
+			t.Errorf("expected %s in script:\n%s", p, s)
+		}
+	}
+
+	if strings.HasSuffix(s, "\\\n") {
+		t.Errorf("unexpected line continuation at end of script:\n%s", s)
+	}
+}
+
+func TestCSVWriterSharedClasses(t *testing.T) {
+	dir, err := ioutil.TempDir("", "writer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	written := make(map[string]string)
+
+	classes := []*class{
+		{ID: "http://x/a", Label: "A"},
+		{ID: "http://x/b", Label: "B", Parents: []string{"http://x/a"}, Relations: []relation{{Type: "part_of", Target: "http://x/a"}}},
+	}
+
+	for _, id := range []string{"v1", "v2"} {
+		w, err := newCSVWriter(dir, &vocab{ID: id}, false, written)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, c := range classes {
+			if err := w.Write(c); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// Duplicates within a vocabulary are written once as well.
+		if err := w.Write(classes[1]); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// lines returns the rows of an output file without its header.
+	lines := func(name string) []string {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Split(strings.TrimSpace(string(b)), "\n")[1:]
+	}
+
+	tests := []struct {
+		file string
+		rows int
+	}{
+		{"v1_nodes_class.csv", 2},
+		{"v1_rels_subclassof.csv", 1},
+		{"v1_rels_relationship.csv", 1},
+		{"v1_rels_classof.csv", 2},
+		{"v2_nodes_class.csv", 0},
+		{"v2_rels_subclassof.csv", 0},
+		{"v2_rels_relationship.csv", 0},
+		{"v2_rels_classof.csv", 2},
+	}
+
+	for _, test := range tests {
+		if rows := lines(test.file); len(rows) != test.rows {
+			t.Errorf("%s: expected %d rows, got %d: %v", test.file, test.rows, len(rows), rows)
+		}
+	}
+
+	if rows := lines("v2_rels_classof.csv"); rows[1] != "http://x/b,classOf,v2" {
+		t.Errorf("unexpected classOf row %s", rows[1])
+	}
+}
@@ -0,0 +1,403 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/chop-dbhi/go-bioportal"
+)
+
+// listFlag is a comma-separated list of values. The flag may be repeated.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(s string) error {
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: bioportal %s [flags] %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func fmtFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 3, 64)
+}
+
+func searchCmd(c *bioportal.Client, args []string) (interface{}, error) {
+	var (
+		ontologies    listFlag
+		cuis          listFlag
+		semanticTypes listFlag
+		exact         bool
+		suggest       bool
+		definitions   bool
+		properties    bool
+		obsolete      bool
+		page          int
+		pagesize      int
+	)
+
+	fs := newFlagSet("search", "<query>")
+	fs.Var(&ontologies, "ontologies", "Ontology acronyms to search.")
+	fs.Var(&cuis, "cui", "UMLS CUIs to filter by.")
+	fs.Var(&semanticTypes, "semantic-types", "Semantic types to filter by, as TUIs or names.")
+	fs.BoolVar(&exact, "exact", false, "Require an exact match.")
+	fs.BoolVar(&suggest, "suggest", false, "Perform a type-ahead search.")
+	fs.BoolVar(&definitions, "definitions", false, "Require classes to have a definition.")
+	fs.BoolVar(&properties, "properties", false, "Also search properties.")
+	fs.BoolVar(&obsolete, "obsolete", false, "Also search obsolete classes.")
+	fs.IntVar(&page, "page", 1, "Page number.")
+	fs.IntVar(&pagesize, "pagesize", 0, "Page size.")
+	fs.Parse(args)
+
+	tuis, err := bioportal.ResolveSemanticTypes(semanticTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	b := bioportal.NewSearch(strings.Join(fs.Args(), " ")).
+		Ontologies(ontologies...).
+		CUI(cuis...).
+		SemanticTypes(tuis...).
+		Page(page).
+		Pagesize(pagesize)
+
+	if exact {
+		b.ExactMatch()
+	}
+	if suggest {
+		b.Suggest()
+	}
+	if definitions {
+		b.RequireDefinitions()
+	}
+	if properties {
+		b.AlsoSearchProperties()
+	}
+	if obsolete {
+		b.AlsoSearchObsolete()
+	}
+
+	opts, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.Search(*opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &result{
+		value:  res,
+		header: []string{"id", "label", "ontology", "match_type", "synonyms", "cui", "semantic_types"},
+	}
+
+	for i := range res.Collection {
+		sc := &res.Collection[i]
+		r.add(
+			sc.ID,
+			sc.PrefLabel,
+			sc.OntologyAcronym(),
+			sc.MatchType,
+			join(sc.Synonym),
+			join(sc.Cui),
+			join(sc.SemanticTypeLabels()),
+		)
+	}
+
+	return r, nil
+}
+
+func annotateCmd(c *bioportal.Client, args []string) (interface{}, error) {
+	var (
+		ontologies    listFlag
+		semanticTypes listFlag
+		stopWords     listFlag
+	)
+
+	opts := bioportal.DefaultAnnotateOptions()
+
+	fs := newFlagSet("annotate", "[<text> | -]")
+	fs.Var(&ontologies, "ontologies", "Ontology acronyms to annotate with.")
+	fs.Var(&semanticTypes, "semantic-types", "Semantic types to filter by, as TUIs or names.")
+	fs.Var(&stopWords, "stop-words", "Words to ignore.")
+	fs.BoolVar(&opts.LongestOnly, "longest-only", false, "Only return the longest match.")
+	fs.BoolVar(&opts.WholeWordOnly, "whole-word-only", true, "Only match whole words.")
+	fs.BoolVar(&opts.ExcludeNumbers, "exclude-numbers", false, "Exclude numbers.")
+	fs.BoolVar(&opts.ExcludeSynonyms, "exclude-synonyms", false, "Exclude synonym matches.")
+	fs.BoolVar(&opts.ExpandMappings, "expand-mappings", false, "Include mapped classes.")
+	fs.UintVar(&opts.MinimumMatchLength, "min-length", 0, "Minimum match length.")
+	fs.Parse(args)
+
+	text := strings.Join(fs.Args(), " ")
+	if text == "" || text == "-" {
+		b, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		text = string(b)
+	}
+
+	tuis, err := bioportal.ResolveSemanticTypes(semanticTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Text = text
+	opts.Ontologies = ontologies
+	opts.SemanticTypes = tuis
+	opts.StopWords = stopWords
+
+	res, err := c.Annotate(*opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &result{
+		value:  res,
+		header: []string{"id", "ontology", "from", "to", "match_type", "text"},
+	}
+
+	for _, a := range res {
+		ont := a.AnnotatedClass.Links.Ontology
+		ont = ont[strings.LastIndex(ont, "/")+1:]
+
+		for _, m := range a.Annotations {
+			r.add(
+				a.AnnotatedClass.ID,
+				ont,
+				strconv.Itoa(m.From),
+				strconv.Itoa(m.To),
+				m.MatchType,
+				m.Text,
+			)
+		}
+	}
+
+	return r, nil
+}
+
+func recommendCmd(c *bioportal.Client, args []string) (interface{}, error) {
+	var (
+		ontologies listFlag
+		keywords   bool
+		sets       bool
+	)
+
+	opts := bioportal.DefaultRecommendOptions()
+
+	fs := newFlagSet("recommend", "<term>...")
+	fs.Var(&ontologies, "ontologies", "Ontology acronyms to consider.")
+	fs.BoolVar(&keywords, "keywords", false, "Treat the input as keywords rather than text.")
+	fs.BoolVar(&sets, "sets", false, "Recommend sets of ontologies.")
+	fs.IntVar(&opts.MaxElementsSet, "max-set", opts.MaxElementsSet, "Maximum ontologies per set.")
+	fs.Parse(args)
+
+	if keywords {
+		opts.InputType = bioportal.RecommendKeywords
+	}
+	if sets {
+		opts.OutputType = bioportal.RecommendSets
+	}
+
+	opts.Terms = fs.Args()
+	opts.Ontologies = ontologies
+
+	res, err := c.Recommend(*opts)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &result{
+		value:  res,
+		header: []string{"score", "ontologies", "coverage", "specialization", "acceptance", "detail"},
+	}
+
+	for _, rr := range res {
+		var acrs []string
+		for _, o := range rr.Ontologies {
+			acrs = append(acrs, o.Acronym)
+		}
+
+		r.add(
+			fmtFloat(rr.EvaluationScore),
+			join(acrs),
+			fmtFloat(rr.CoverageResult.NormalizedScore),
+			fmtFloat(rr.SpecializationResult.NormalizedScore),
+			fmtFloat(rr.AcceptanceResult.NormalizedScore),
+			fmtFloat(rr.DetailResult.NormalizedScore),
+		)
+	}
+
+	return r, nil
+}
+
+func classCmd(c *bioportal.Client, args []string) (interface{}, error) {
+	fs := newFlagSet("class", "<ontology> <class>")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		return nil, errors.New("ontology and class required")
+	}
+
+	cl, err := c.Class(fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return nil, err
+	}
+
+	r := &result{
+		value:  cl,
+		header: []string{"id", "label", "synonyms", "definitions", "cui"},
+	}
+
+	r.add(cl.ID, cl.PrefLabel, join(cl.Synonym), join(cl.Definition), join(cl.CUIs()))
+
+	return r, nil
+}
+
+func ontologiesCmd(c *bioportal.Client, args []string) (interface{}, error) {
+	var (
+		groups     listFlag
+		categories listFlag
+	)
+
+	opts := bioportal.DefaultBaseOptions()
+
+	fs := newFlagSet("ontologies", "")
+	fs.Var(&groups, "group", "Only include ontologies in these groups.")
+	fs.Var(&categories, "category", "Only include ontologies in these categories.")
+	fs.BoolVar(&opts.IncludeViews, "views", false, "Include views.")
+	fs.Parse(args)
+
+	res, err := c.FilterOntologies(*opts, bioportal.OntologyFilter{
+		Groups:     groups,
+		Categories: categories,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r := &result{
+		value:  res,
+		header: []string{"acronym", "name", "id"},
+	}
+
+	for _, o := range res {
+		r.add(o.Acronym, o.Name, o.ID)
+	}
+
+	return r, nil
+}
+
+func mappingsCmd(c *bioportal.Client, args []string) (interface{}, error) {
+	fs := newFlagSet("mappings", "<ontology> <class>")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		return nil, errors.New("ontology and class required")
+	}
+
+	res, err := c.Mappings(fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		return nil, err
+	}
+
+	r := &result{
+		value:  res,
+		header: []string{"source", "id", "ontology"},
+	}
+
+	for _, m := range res {
+		src := sourceClass(m, fs.Arg(0), fs.Arg(1))
+
+		for i := range m.Classes {
+			cl := &m.Classes[i]
+			if cl == src {
+				continue
+			}
+			r.add(m.Source, cl.ID, cl.OntologyAcronym())
+		}
+	}
+
+	return r, nil
+}
+
+// sourceClass returns the class of the mapping the mappings were requested
+// for, which may have been given by its full ID or a short one. It is the
+// only class of the mapping in the ontology, or else the one with the full
+// ID.
+func sourceClass(m *bioportal.Mapping, ontology, class string) *bioportal.Class {
+	var src *bioportal.Class
+
+	for i := range m.Classes {
+		if m.Classes[i].ID == class {
+			return &m.Classes[i]
+		}
+	}
+
+	for i := range m.Classes {
+		if !strings.EqualFold(m.Classes[i].OntologyAcronym(), ontology) {
+			continue
+		}
+		if src != nil {
+			return nil
+		}
+		src = &m.Classes[i]
+	}
+
+	return src
+}
+
+func downloadCmd(c *bioportal.Client, args []string) (interface{}, error) {
+	var (
+		output string
+		opts   bioportal.DownloadOptions
+	)
+
+	fs := newFlagSet("download", "<ontology>")
+	fs.StringVar(&output, "o", "", "Output file. Defaults to stdout.")
+	fs.StringVar(&opts.Format, "export", "", "Export format, such as csv. Defaults to the source file.")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return nil, errors.New("ontology required")
+	}
+
+	if output == "" {
+		_, err := c.Download(os.Stdout, fs.Arg(0), opts)
+		return nil, err
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return nil, err
+	}
+
+	// Do not leave a partial file behind.
+	if _, err := c.Download(f, fs.Arg(0), opts); err != nil {
+		f.Close()
+		os.Remove(output)
+		return nil, err
+	}
+
+	return nil, f.Close()
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/chop-dbhi/go-bioportal"
+)
+
+const usage = `usage: bioportal [-key <apikey>] [-format json|table|csv] <command> [args]
+
+Commands:
+  search      Search classes across ontologies.
+  annotate    Annotate text with ontology classes.
+  recommend   Recommend ontologies for a set of terms.
+  class       Get a class by ontology and class ID.
+  ontologies  List ontologies, optionally by group or category.
+  mappings    List the mappings of a class.
+  download    Download the latest submission of an ontology.
+
+The API key is read from the -key flag, the BIOPORTAL_API_KEY environment
+variable or the api_key entry in ~/.bioportal, in that order.
+`
+
+type command func(c *bioportal.Client, args []string) (interface{}, error)
+
+var commands = map[string]command{
+	"search":     searchCmd,
+	"annotate":   annotateCmd,
+	"recommend":  recommendCmd,
+	"class":      classCmd,
+	"ontologies": ontologiesCmd,
+	"mappings":   mappingsCmd,
+	"download":   downloadCmd,
+}
+
+// readConfig reads key = value pairs from a file. Blank lines and lines
+// starting with # are ignored.
+func readConfig(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	conf := make(map[string]string)
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		toks := strings.SplitN(line, "=", 2)
+		if len(toks) != 2 {
+			continue
+		}
+
+		conf[strings.TrimSpace(toks[0])] = strings.TrimSpace(toks[1])
+	}
+
+	return conf, sc.Err()
+}
+
+func apiKey(key string) string {
+	if key != "" {
+		return key
+	}
+
+	if key = os.Getenv("BIOPORTAL_API_KEY"); key != "" {
+		return key
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	conf, err := readConfig(filepath.Join(home, ".bioportal"))
+	if err != nil {
+		return ""
+	}
+
+	return conf["api_key"]
+}
+
+func main() {
+	log.SetFlags(0)
+
+	var (
+		key    string
+		format string
+	)
+
+	flag.StringVar(&key, "key", "", "BioPortal API key.")
+	flag.StringVar(&format, "format", "json", "Output format: json, table or csv.")
+
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+	}
+
+	flag.Parse()
+
+	// Fail before making any request.
+	if err := checkFormat(format); err != nil {
+		log.Fatal(err)
+	}
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		var names []string
+		for n := range commands {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		log.Fatalf("unknown command %q, expected one of: %s", args[0], strings.Join(names, ", "))
+	}
+
+	key = apiKey(key)
+	if key == "" {
+		log.Fatal("API key required")
+	}
+
+	c := bioportal.NewClient(key)
+
+	res, err := cmd(c, args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Commands that write their own output return nil.
+	if res == nil {
+		return
+	}
+
+	if err := write(os.Stdout, format, res); err != nil {
+		log.Fatal(err)
+	}
+}
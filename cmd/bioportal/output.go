@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// result pairs the decoded API response, which is used for JSON output, with
+// a tabular view of it used for the table and CSV output.
+type result struct {
+	value  interface{}
+	header []string
+	rows   [][]string
+}
+
+func (r *result) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.value)
+}
+
+func (r *result) add(row ...string) {
+	r.rows = append(r.rows, row)
+}
+
+func join(a []string) string {
+	return strings.Join(a, "|")
+}
+
+// checkFormat returns an error if the output format is not supported.
+func checkFormat(format string) error {
+	switch format {
+	case "json", "table", "csv":
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+func write(w io.Writer, format string, v interface{}) error {
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	r, ok := v.(*result)
+	if !ok {
+		return fmt.Errorf("%s output not supported for this command", format)
+	}
+
+	if format == "csv" {
+		cw := csv.NewWriter(w)
+		cw.Write(r.header)
+		cw.WriteAll(r.rows)
+		return cw.Error()
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(r.header, "\t"))
+	for _, row := range r.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,169 @@
+package bioportal
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CUIs returns the UMLS CUIs assigned to the class.
+func (c *Class) CUIs() []string {
+	var cuis []string
+	for _, v := range c.Cui {
+		if s, ok := v.(string); ok {
+			cuis = append(cuis, s)
+		}
+	}
+	return cuis
+}
+
+// OntologyAcronym returns the acronym of the ontology the class belongs to.
+func (c *Class) OntologyAcronym() string {
+	return lastSegment(c.Links.Ontology)
+}
+
+// ConceptMatch is a class in another ontology considered equivalent to a
+// source class.
+type ConceptMatch struct {
+	ID       string
+	Label    string
+	Ontology string
+
+	// CUIs are the CUIs shared with the source class.
+	CUIs []string
+
+	// Sources lists how the match was found, either "CUI" or the source of
+	// a mapping such as "LOOM" or "SAME_URI".
+	Sources []string
+}
+
+// ClassesByCUI returns the classes annotated with a UMLS CUI grouped by
+// ontology acronym. If ontologies is empty, all ontologies are searched.
+func (c *Client) ClassesByCUI(cui string, ontologies []string) (map[string][]*SearchClass, error) {
+	if !cuiRe.MatchString(cui) {
+		return nil, fmt.Errorf("invalid CUI %q", cui)
+	}
+
+	opts := DefaultSearchOptions()
+	opts.Query = cui
+	opts.CUI = []string{cui}
+	opts.Ontologies = ontologies
+	opts.AlsoSearchProperties = true
+	opts.Pagesize = 100
+	opts.DisplayContext = false
+
+	groups := make(map[string][]*SearchClass)
+
+	for {
+		res, err := c.Search(*opts)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range res.Collection {
+			sc := &res.Collection[i]
+			acr := sc.OntologyAcronym()
+			groups[acr] = append(groups[acr], sc)
+		}
+
+		if res.Page >= res.PageCount {
+			break
+		}
+
+		opts.Page = res.Page + 1
+	}
+
+	return groups, nil
+}
+
+// EquivalentClasses finds classes in other ontologies equivalent to the
+// given class, either by sharing a CUI or through a mapping. Results are
+// grouped by ontology acronym and restricted to ontologies if non-empty.
+// Classes of the source ontology itself are not returned.
+func (c *Client) EquivalentClasses(ontology, class string, ontologies []string) (map[string][]*ConceptMatch, error) {
+	src, err := c.Class(ontology, class)
+	if err != nil {
+		return nil, err
+	}
+
+	if src.ID == "" {
+		return nil, errors.New("class not found")
+	}
+
+	allowed := make(map[string]struct{}, len(ontologies))
+	for _, o := range ontologies {
+		allowed[o] = struct{}{}
+	}
+
+	matches := make(map[string]*ConceptMatch)
+	groups := make(map[string][]*ConceptMatch)
+
+	add := func(id, label, acr string) *ConceptMatch {
+		if id == src.ID || acr == ontology {
+			return nil
+		}
+
+		if len(allowed) > 0 {
+			if _, ok := allowed[acr]; !ok {
+				return nil
+			}
+		}
+
+		m, ok := matches[id]
+		if !ok {
+			m = &ConceptMatch{
+				ID:       id,
+				Label:    label,
+				Ontology: acr,
+			}
+			matches[id] = m
+			groups[acr] = append(groups[acr], m)
+		}
+
+		if m.Label == "" {
+			m.Label = label
+		}
+
+		return m
+	}
+
+	for _, cui := range src.CUIs() {
+		res, err := c.ClassesByCUI(cui, ontologies)
+		if err != nil {
+			return nil, err
+		}
+
+		for acr, classes := range res {
+			for _, sc := range classes {
+				if m := add(sc.ID, sc.PrefLabel, acr); m != nil {
+					m.CUIs = appendUnique(m.CUIs, cui)
+					m.Sources = appendUnique(m.Sources, "CUI")
+				}
+			}
+		}
+	}
+
+	mappings, err := c.Mappings(ontology, class)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, mp := range mappings {
+		for i := range mp.Classes {
+			cl := &mp.Classes[i]
+			if m := add(cl.ID, cl.PrefLabel, cl.OntologyAcronym()); m != nil {
+				m.Sources = appendUnique(m.Sources, mp.Source)
+			}
+		}
+	}
+
+	return groups, nil
+}
+
+func appendUnique(a []string, s string) []string {
+	for _, x := range a {
+		if x == s {
+			return a
+		}
+	}
+	return append(a, s)
+}
@@ -0,0 +1,151 @@
+package bioportal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testOntologies = "http://data.bioontology.org/ontologies/"
+
+func testClass(acr, id, label string) map[string]interface{} {
+	return map[string]interface{}{
+		"@id":       id,
+		"prefLabel": label,
+		"links":     map[string]string{"ontology": testOntologies + acr},
+	}
+}
+
+func TestEquivalentClasses(t *testing.T) {
+	const srcID = "http://ncicb.nci.nih.gov/xml/owl/EVS/Thesaurus.owl#C3224"
+
+	var searches []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var res interface{}
+
+		switch {
+		case r.URL.Path == "/search":
+			q := r.URL.Query()
+			searches = append(searches, q.Get("q")+"/"+q.Get("page"))
+
+			switch q.Get("q") + "/" + q.Get("page") {
+			case "C0025202/1":
+				res = map[string]interface{}{
+					"page":      1,
+					"pageCount": 2,
+					"collection": []interface{}{
+						testClass("MESH", "http://purl.bioontology.org/ontology/MESH/D008545", "Melanoma"),
+						// Other classes of the source ontology are skipped.
+						testClass("NCIT", "http://ncicb.nci.nih.gov/xml/owl/EVS/Thesaurus.owl#C9999", "Other"),
+					},
+				}
+			case "C0025202/2":
+				res = map[string]interface{}{
+					"page":       2,
+					"pageCount":  2,
+					"collection": []interface{}{testClass("HP", "http://purl.obolibrary.org/obo/HP_0002861", "")},
+				}
+			case "C1111111/1":
+				res = map[string]interface{}{
+					"page":       1,
+					"pageCount":  1,
+					"collection": []interface{}{testClass("MESH", "http://purl.bioontology.org/ontology/MESH/D008545", "Melanoma")},
+				}
+			default:
+				t.Errorf("unexpected search %s", r.URL.RawQuery)
+			}
+
+		case strings.HasSuffix(r.URL.Path, "/mappings"):
+			res = []interface{}{
+				map[string]interface{}{
+					"source": "LOOM",
+					"classes": []interface{}{
+						testClass("NCIT", srcID, "Melanoma"),
+						testClass("MESH", "http://purl.bioontology.org/ontology/MESH/D008545", "Melanoma"),
+						testClass("HP", "http://purl.obolibrary.org/obo/HP_0002861", "Melanoma"),
+					},
+				},
+				map[string]interface{}{
+					"source": "SAME_URI",
+					"classes": []interface{}{
+						testClass("NCIT", srcID, "Melanoma"),
+						testClass("SNOMEDCT", "http://purl.bioontology.org/ontology/SNOMEDCT/2092003", "Malignant melanoma"),
+					},
+				},
+			}
+
+		case strings.HasPrefix(r.URL.Path, "/ontologies/NCIT/classes/"):
+			c := testClass("NCIT", srcID, "Melanoma")
+			c["cui"] = []string{"C0025202", "C1111111"}
+			res = c
+
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		json.NewEncoder(w).Encode(res)
+	}))
+	defer srv.Close()
+
+	defer func(u string) { BaseURL = u }(BaseURL)
+	BaseURL = srv.URL
+
+	c := NewClient("key")
+
+	groups, err := c.EquivalentClasses("NCIT", srcID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exp := []string{"C0025202/1", "C0025202/2", "C1111111/1"}; !reflect.DeepEqual(searches, exp) {
+		t.Errorf("expected searches %v, got %v", exp, searches)
+	}
+
+	exp := map[string][]*ConceptMatch{
+		"MESH": {{
+			ID:       "http://purl.bioontology.org/ontology/MESH/D008545",
+			Label:    "Melanoma",
+			Ontology: "MESH",
+			CUIs:     []string{"C0025202", "C1111111"},
+			Sources:  []string{"CUI", "LOOM"},
+		}},
+		// The label is taken from the mapping.
+		"HP": {{
+			ID:       "http://purl.obolibrary.org/obo/HP_0002861",
+			Label:    "Melanoma",
+			Ontology: "HP",
+			CUIs:     []string{"C0025202"},
+			Sources:  []string{"CUI", "LOOM"},
+		}},
+		"SNOMEDCT": {{
+			ID:       "http://purl.bioontology.org/ontology/SNOMEDCT/2092003",
+			Label:    "Malignant melanoma",
+			Ontology: "SNOMEDCT",
+			Sources:  []string{"SAME_URI"},
+		}},
+	}
+
+	if !reflect.DeepEqual(groups, exp) {
+		for acr, ms := range groups {
+			for _, m := range ms {
+				t.Logf("%s: %+v", acr, m)
+			}
+		}
+		t.Errorf("unexpected matches")
+	}
+
+	groups, err = c.EquivalentClasses("NCIT", srcID, []string{"SNOMEDCT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(groups) != 1 || len(groups["SNOMEDCT"]) != 1 {
+		t.Errorf("expected only SNOMEDCT matches, got %v", groups)
+	}
+}
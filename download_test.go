@@ -0,0 +1,49 @@
+package bioportal
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDownloadTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("a,b\n"))
+		w.(http.Flusher).Flush()
+
+		// Slower than the client timeout.
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte("1,2\n"))
+	}))
+	defer srv.Close()
+
+	defer func(u string) { BaseURL = u }(BaseURL)
+	BaseURL = srv.URL
+
+	c := NewClient("key")
+	c.HTTP.Timeout = 20 * time.Millisecond
+
+	var buf bytes.Buffer
+	if _, err := c.Download(&buf, "TEST", DownloadOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != "a,b\n1,2\n" {
+		t.Errorf("unexpected download %q", buf.String())
+	}
+
+	buf.Reset()
+	if _, err := c.DownloadSubmission(&buf, "TEST", 2, DownloadOptions{Format: "csv"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != "a,b\n1,2\n" {
+		t.Errorf("unexpected submission download %q", buf.String())
+	}
+
+	if c.HTTP.Timeout != 20*time.Millisecond {
+		t.Errorf("expected the client timeout to be kept, got %s", c.HTTP.Timeout)
+	}
+}
@@ -0,0 +1,60 @@
+package bioportal
+
+type Group struct {
+	Acronym     string   `json:"acronym"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Created     string   `json:"created"`
+	Ontologies  []string `json:"ontologies"`
+	ID          string   `json:"@id"`
+	Type        string   `json:"@type"`
+	Links       struct {
+		Ontologies string `json:"ontologies"`
+	} `json:"links"`
+}
+
+type Category struct {
+	Acronym        string   `json:"acronym"`
+	Name           string   `json:"name"`
+	Description    string   `json:"description"`
+	Created        string   `json:"created"`
+	ParentCategory string   `json:"parentCategory"`
+	Ontologies     []string `json:"ontologies"`
+	ID             string   `json:"@id"`
+	Type           string   `json:"@type"`
+	Links          struct {
+		Ontologies string `json:"ontologies"`
+	} `json:"links"`
+}
+
+// OntologyFilter narrows the ontology catalog to the ontologies that belong
+// to any of the listed groups and any of the listed categories. Empty fields
+// do not constrain the result.
+type OntologyFilter struct {
+	Groups     []string
+	Categories []string
+}
+
+// filterOntologies returns the ontologies whose acronyms are present in
+// every non-nil set, preserving catalog order.
+func filterOntologies(onts []*Ontology, sets ...map[string]struct{}) []*Ontology {
+	var res []*Ontology
+
+	for _, o := range onts {
+		ok := true
+		for _, s := range sets {
+			if s == nil {
+				continue
+			}
+			if _, in := s[o.Acronym]; !in {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			res = append(res, o)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,70 @@
+package bioportal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterOntologies(t *testing.T) {
+	onts := []*Ontology{{Acronym: "NCIT"}, {Acronym: "HP"}, {Acronym: "MESH"}, {Acronym: "GO"}}
+
+	set := func(acrs ...string) map[string]struct{} {
+		s := make(map[string]struct{})
+		for _, a := range acrs {
+			s[a] = struct{}{}
+		}
+		return s
+	}
+
+	tests := []struct {
+		name       string
+		groups     map[string]struct{}
+		categories map[string]struct{}
+		exp        []string
+	}{
+		{"no filter", nil, nil, []string{"NCIT", "HP", "MESH", "GO"}},
+		{"group only", set("MESH", "NCIT"), nil, []string{"NCIT", "MESH"}},
+		{"category only", nil, set("GO"), []string{"GO"}},
+		{"intersection", set("NCIT", "HP", "MESH"), set("HP", "GO", "MESH"), []string{"HP", "MESH"}},
+		{"disjoint", set("NCIT"), set("GO"), nil},
+		{"empty set", set(), nil, nil},
+		{"unknown acronym", set("XYZ", "HP"), nil, []string{"HP"}},
+	}
+
+	for _, test := range tests {
+		var acrs []string
+		for _, o := range filterOntologies(onts, test.groups, test.categories) {
+			acrs = append(acrs, o.Acronym)
+		}
+
+		if !reflect.DeepEqual(acrs, test.exp) {
+			t.Errorf("%s: expected %v, got %v", test.name, test.exp, acrs)
+		}
+	}
+}
+
+func TestAcronymSet(t *testing.T) {
+	c := NewClient("key")
+
+	fetch := func(k string) ([]*Ontology, error) {
+		return map[string][]*Ontology{
+			"UMLS": {{Acronym: "NCIT"}, {Acronym: "MESH"}},
+			"OBO":  {{Acronym: "HP"}, {Acronym: "NCIT"}},
+		}[k], nil
+	}
+
+	if s, err := c.acronymSet(nil, fetch); err != nil || s != nil {
+		t.Errorf("expected a nil set without keys, got %v, %v", s, err)
+	}
+
+	s, err := c.acronymSet([]string{"UMLS", "OBO"}, fetch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The sets of several keys are combined.
+	exp := map[string]struct{}{"NCIT": {}, "MESH": {}, "HP": {}}
+	if !reflect.DeepEqual(s, exp) {
+		t.Errorf("expected %v, got %v", exp, s)
+	}
+}
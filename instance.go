@@ -0,0 +1,82 @@
+package bioportal
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// PropertyValue is a single value of an instance property. Values that refer
+// to other resources have IRI set, otherwise they are literals.
+type PropertyValue struct {
+	Value    string
+	IRI      bool
+	Language string
+	Datatype string
+}
+
+func (v PropertyValue) String() string {
+	return v.Value
+}
+
+func (v *PropertyValue) UnmarshalJSON(b []byte) error {
+	var raw interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	switch x := raw.(type) {
+	case string:
+		v.Value = x
+
+	case float64, bool:
+		v.Value = fmt.Sprint(x)
+
+	case map[string]interface{}:
+		if id, ok := x["@id"].(string); ok {
+			v.Value = id
+			v.IRI = true
+			return nil
+		}
+
+		v.Value = fmt.Sprint(x["@value"])
+		v.Language, _ = x["@language"].(string)
+		v.Datatype, _ = x["@type"].(string)
+
+	case nil:
+
+	default:
+		return fmt.Errorf("unsupported property value: %s", string(b))
+	}
+
+	return nil
+}
+
+type Instance struct {
+	Label      []string                   `json:"label"`
+	Types      []string                   `json:"types"`
+	Properties map[string][]PropertyValue `json:"properties"`
+	ID         string                     `json:"@id"`
+	Type       string                     `json:"@type"`
+	Links      struct {
+		Self     string `json:"self"`
+		Ontology string `json:"ontology"`
+	} `json:"links"`
+}
+
+// Property returns the values of a property by its IRI.
+func (i *Instance) Property(iri string) []PropertyValue {
+	return i.Properties[iri]
+}
+
+type InstancesPaginated struct {
+	Page       int         `json:"page"`
+	PageCount  int         `json:"pageCount"`
+	TotalCount int         `json:"totalCount"`
+	PrevPage   interface{} `json:"prevPage"`
+	NextPage   interface{} `json:"nextPage"`
+	Links      struct {
+		NextPage interface{} `json:"nextPage"`
+		PrevPage interface{} `json:"prevPage"`
+	} `json:"links"`
+	Collection []Instance `json:"collection"`
+}
@@ -0,0 +1,38 @@
+package bioportal
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestInstanceProperties(t *testing.T) {
+	b := []byte(`{
+		"@id": "http://example.org/onto#red",
+		"label": ["Red"],
+		"properties": {
+			"http://example.org/onto#code": ["R", 1],
+			"http://example.org/onto#partOf": [{"@id": "http://example.org/onto#palette"}],
+			"http://www.w3.org/2000/01/rdf-schema#comment": [{"@value": "rouge", "@language": "fr"}]
+		}
+	}`)
+
+	var i Instance
+	if err := json.Unmarshal(b, &i); err != nil {
+		t.Fatal(err)
+	}
+
+	code := i.Property("http://example.org/onto#code")
+	if len(code) != 2 || code[0].Value != "R" || code[1].Value != "1" {
+		t.Errorf("unexpected code values: %v", code)
+	}
+
+	part := i.Property("http://example.org/onto#partOf")
+	if len(part) != 1 || !part[0].IRI {
+		t.Errorf("expected IRI value, got %v", part)
+	}
+
+	comment := i.Property("http://www.w3.org/2000/01/rdf-schema#comment")
+	if len(comment) != 1 || comment[0].Language != "fr" {
+		t.Errorf("expected language tag, got %v", comment)
+	}
+}
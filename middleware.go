@@ -0,0 +1,97 @@
+package bioportal
+
+import (
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// RoundTripperFunc adapts a function to the http.RoundTripper interface.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f RoundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// Middleware wraps the transport used for every request made by a Client.
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+// HeaderMiddleware sets the headers on every request, such as tracing IDs.
+func HeaderMiddleware(h http.Header) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			r = r.Clone(r.Context())
+			for k, v := range h {
+				r.Header[k] = v
+			}
+			return next.RoundTrip(r)
+		})
+	}
+}
+
+// LoggingMiddleware logs the method, path, status and latency of every
+// request. If l is nil the standard logger is used.
+func LoggingMiddleware(l *log.Logger) Middleware {
+	if l == nil {
+		l = log.New(log.Writer(), "", log.LstdFlags)
+	}
+
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			t0 := time.Now()
+			resp, err := next.RoundTrip(r)
+			d := time.Since(t0)
+
+			if err != nil {
+				l.Printf("%s %s error %s (%s)", r.Method, r.URL.Path, err, d)
+			} else {
+				l.Printf("%s %s %d (%s)", r.Method, r.URL.Path, resp.StatusCode, d)
+			}
+
+			return resp, err
+		})
+	}
+}
+
+// Metrics holds counters updated by MetricsMiddleware. It is safe for
+// concurrent use.
+type Metrics struct {
+	requests int64
+	errors   int64
+	latency  int64
+}
+
+// Requests returns the number of requests sent.
+func (m *Metrics) Requests() int64 {
+	return atomic.LoadInt64(&m.requests)
+}
+
+// Errors returns the number of requests that failed or returned an error
+// status.
+func (m *Metrics) Errors() int64 {
+	return atomic.LoadInt64(&m.errors)
+}
+
+// Latency returns the total time spent waiting for responses.
+func (m *Metrics) Latency() time.Duration {
+	return time.Duration(atomic.LoadInt64(&m.latency))
+}
+
+func MetricsMiddleware(m *Metrics) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+			t0 := time.Now()
+			resp, err := next.RoundTrip(r)
+
+			atomic.AddInt64(&m.requests, 1)
+			atomic.AddInt64(&m.latency, int64(time.Since(t0)))
+
+			if err != nil || resp.StatusCode >= 400 {
+				atomic.AddInt64(&m.errors, 1)
+			}
+
+			return resp, err
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package bioportal
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Trace-Id") != "abc" {
+			w.WriteHeader(400)
+			w.Write([]byte(`{"status": 400, "errors": ["missing trace header"]}`))
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+
+	defer func(u string) { BaseURL = u }(BaseURL)
+	BaseURL = srv.URL
+
+	var (
+		buf bytes.Buffer
+		m   Metrics
+	)
+
+	c := NewClient("key")
+	c.Use(
+		LoggingMiddleware(log.New(&buf, "", 0)),
+		MetricsMiddleware(&m),
+		HeaderMiddleware(http.Header{"X-Trace-Id": {"abc"}}),
+	)
+
+	if _, err := c.Groups(); err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Requests() != 1 || m.Errors() != 0 {
+		t.Errorf("unexpected metrics: %d requests, %d errors", m.Requests(), m.Errors())
+	}
+
+	if !strings.HasPrefix(buf.String(), "GET /groups 200") {
+		t.Errorf("unexpected log output: %q", buf.String())
+	}
+}
@@ -0,0 +1,204 @@
+package bioportal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ndjsonWriter writes one JSON value per line, optionally flattening nested
+// objects into dotted keys.
+type ndjsonWriter struct {
+	w       io.Writer
+	flatten bool
+	buf     bytes.Buffer
+	n       int
+}
+
+func (nw *ndjsonWriter) write(raw json.RawMessage) error {
+	nw.buf.Reset()
+
+	if nw.flatten {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+
+		if m, ok := v.(map[string]interface{}); ok {
+			flat := make(map[string]interface{}, len(m))
+			flattenInto(flat, "", m)
+			v = flat
+		}
+
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		nw.buf.Write(b)
+	} else if err := json.Compact(&nw.buf, raw); err != nil {
+		return err
+	}
+
+	nw.buf.WriteByte('\n')
+
+	if _, err := nw.w.Write(nw.buf.Bytes()); err != nil {
+		return err
+	}
+
+	nw.n++
+	return nil
+}
+
+// flattenInto copies m into dst, joining the keys of nested objects with
+// dots. Arrays are kept as-is.
+func flattenInto(dst map[string]interface{}, prefix string, m map[string]interface{}) {
+	for k, v := range m {
+		if prefix != "" {
+			k = prefix + "." + k
+		}
+
+		if sub, ok := v.(map[string]interface{}); ok {
+			flattenInto(dst, k, sub)
+		} else {
+			dst[k] = v
+		}
+	}
+}
+
+func expectDelim(dec *json.Decoder, d json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if tok != d {
+		return fmt.Errorf("expected %s, got %v", d, tok)
+	}
+
+	return nil
+}
+
+// streamArray writes each element of the JSON array at the decoder's
+// position.
+func streamArray(dec *json.Decoder, nw *ndjsonWriter) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+
+		if err := nw.write(raw); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(dec, ']')
+}
+
+// streamCollection writes each element of the object's collection array.
+// The other members are skipped and returned so paging fields can be
+// inspected.
+func streamCollection(dec *json.Decoder, nw *ndjsonWriter) (map[string]json.RawMessage, error) {
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	rest := make(map[string]json.RawMessage)
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		key, ok := tok.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected object key, got %v", tok)
+		}
+
+		if key == "collection" {
+			if err := streamArray(dec, nw); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		rest[key] = raw
+	}
+
+	return rest, expectDelim(dec, '}')
+}
+
+// SearchReadNDJSON writes the search results to w as newline-delimited JSON,
+// one class per line, following pages from opts.Page to the last page. If
+// flatten is true, nested objects are flattened into dotted keys. The number
+// of classes written is returned.
+func (c *Client) SearchReadNDJSON(w io.Writer, opts SearchOptions, flatten bool) (int, error) {
+	nw := &ndjsonWriter{w: w, flatten: flatten}
+
+	for {
+		rc, err := c.search(&opts)
+		if err != nil {
+			return nw.n, err
+		}
+
+		rest, err := streamCollection(json.NewDecoder(rc), nw)
+		rc.Close()
+		if err != nil {
+			return nw.n, err
+		}
+
+		var next *int
+		if raw, ok := rest["nextPage"]; ok {
+			if err := json.Unmarshal(raw, &next); err != nil {
+				return nw.n, err
+			}
+		}
+
+		if next == nil || *next <= opts.Page {
+			return nw.n, nil
+		}
+
+		opts.Page = *next
+	}
+}
+
+// AnnotateReadNDJSON writes the annotations to w as newline-delimited JSON,
+// one annotated class per line.
+func (c *Client) AnnotateReadNDJSON(w io.Writer, opts AnnotateOptions, flatten bool) (int, error) {
+	rc, err := c.annotate(&opts)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	nw := &ndjsonWriter{w: w, flatten: flatten}
+	err = streamArray(json.NewDecoder(rc), nw)
+	return nw.n, err
+}
+
+// RecommendReadNDJSON writes the recommendations to w as newline-delimited
+// JSON, one recommendation per line.
+func (c *Client) RecommendReadNDJSON(w io.Writer, opts RecommendOptions, flatten bool) (int, error) {
+	rc, err := c.recommend(&opts)
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+
+	nw := &ndjsonWriter{w: w, flatten: flatten}
+	err = streamArray(json.NewDecoder(rc), nw)
+	return nw.n, err
+}
@@ -0,0 +1,41 @@
+package bioportal
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestStreamCollection(t *testing.T) {
+	body := `{
+		"page": 1,
+		"pageCount": 2,
+		"nextPage": 2,
+		"collection": [
+			{"@id": "a", "links": {"self": "x", "ontology": "y"}},
+			{"@id": "b", "links": {"self": "z"}}
+		]
+	}`
+
+	var buf bytes.Buffer
+	nw := &ndjsonWriter{w: &buf, flatten: true}
+
+	rest, err := streamCollection(json.NewDecoder(strings.NewReader(body)), nw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if nw.n != 2 {
+		t.Errorf("expected 2 lines, got %d", nw.n)
+	}
+
+	if string(rest["nextPage"]) != "2" {
+		t.Errorf("expected nextPage to be kept, got %s", rest["nextPage"])
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if lines[0] != `{"@id":"a","links.ontology":"y","links.self":"x"}` {
+		t.Errorf("unexpected line: %s", lines[0])
+	}
+}
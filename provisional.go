@@ -0,0 +1,33 @@
+package bioportal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProvisionalClass is a proposed class that has not yet been added to an
+// ontology. Creator must be the IRI of a BioPortal user, e.g.
+// https://data.bioontology.org/users/jdoe.
+type ProvisionalClass struct {
+	Label       string   `json:"label,omitempty"`
+	Synonym     []string `json:"synonym,omitempty"`
+	Definition  []string `json:"definition,omitempty"`
+	SubclassOf  string   `json:"subclassOf,omitempty"`
+	Creator     string   `json:"creator,omitempty"`
+	Created     string   `json:"created,omitempty"`
+	Ontology    string   `json:"ontology,omitempty"`
+	PermanentID string   `json:"permanentId,omitempty"`
+	NoteID      string   `json:"noteId,omitempty"`
+	ID          string   `json:"@id,omitempty"`
+	Type        string   `json:"@type,omitempty"`
+}
+
+// provisionalPath returns the request path for a provisional class given
+// either its full IRI or its trailing identifier.
+func provisionalPath(id string) string {
+	if strings.HasPrefix(id, "http://") || strings.HasPrefix(id, "https://") {
+		return id
+	}
+
+	return fmt.Sprintf("/provisional_classes/%s", id)
+}
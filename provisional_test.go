@@ -0,0 +1,100 @@
+package bioportal
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProvisionalClassRequests(t *testing.T) {
+	var (
+		retried bool
+		bodies  = make(map[string]ProvisionalClass)
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil && r.ContentLength > 0 {
+			if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+				t.Errorf("%s: unexpected content type %q", r.Method, ct)
+			}
+
+			var pc ProvisionalClass
+			if err := json.NewDecoder(r.Body).Decode(&pc); err != nil {
+				t.Errorf("%s: %s", r.Method, err)
+			}
+			bodies[r.Method] = pc
+		}
+
+		switch r.Method {
+		case "POST":
+			// The first attempt is rate limited.
+			if !retried {
+				retried = true
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"@id": "http://data.bioontology.org/provisional_classes/1", "label": "Foo"}`))
+		case "PATCH", "DELETE":
+			if r.URL.Path != "/provisional_classes/1" {
+				t.Errorf("%s: unexpected path %s", r.Method, r.URL.Path)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("<html>Bad Gateway</html>"))
+		}
+	}))
+	defer srv.Close()
+
+	defer func(u string) { BaseURL = u }(BaseURL)
+	BaseURL = srv.URL
+
+	c := NewClient("key")
+
+	pc, err := c.CreateProvisionalClass(&ProvisionalClass{
+		Label:   "Foo",
+		Creator: "http://data.bioontology.org/users/jdoe",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !retried {
+		t.Error("expected the rate limited request to be retried")
+	}
+
+	if pc.ID != "http://data.bioontology.org/provisional_classes/1" {
+		t.Errorf("unexpected id %q", pc.ID)
+	}
+
+	if b := bodies["POST"]; b.Label != "Foo" || b.Creator == "" {
+		t.Errorf("unexpected POST body %+v", b)
+	}
+
+	if err := c.UpdateProvisionalClass("1", &ProvisionalClass{Label: "Bar", ID: "x"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if b := bodies["PATCH"]; b.Label != "Bar" || b.ID != "" {
+		t.Errorf("unexpected PATCH body %+v", b)
+	}
+
+	if err := c.DeleteProvisionalClass("1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A non-JSON error body is returned as an error.
+	_, err = c.ProvisionalClass("1")
+
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("expected *APIError, got %v", err)
+	}
+
+	if apiErr.Status != http.StatusBadGateway || !strings.Contains(apiErr.Error(), "Bad Gateway") {
+		t.Errorf("unexpected error %v", apiErr)
+	}
+}
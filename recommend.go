@@ -1,28 +1,142 @@
 package bioportal
 
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+type RecommendInputType int
+
+const (
+	RecommendText     RecommendInputType = 1
+	RecommendKeywords RecommendInputType = 2
+)
+
+type RecommendOutputType int
+
+const (
+	RecommendSingle RecommendOutputType = 1
+	RecommendSets   RecommendOutputType = 2
+)
+
+// Weights used by the server when none are given.
+const (
+	DefaultCoverageWeight       = 0.55
+	DefaultSpecializationWeight = 0.15
+	DefaultAcceptanceWeight     = 0.15
+	DefaultDetailWeight         = 0.15
+)
+
 type RecommendOptions struct {
 	BaseOptions
 
-	Terms                []string `url:"input"`
-	Ontologies           []string `url:"ontologies,omitempty"`
-	InputType            int      `url:"input_type,omitempty"`
-	OutputType           int      `url:"output_type,omitempty"`
-	MaxElementsSet       int      `url:"max_elements_set,omitempty"`
-	CoverageWeight       float32  `url:"wc,omitempty"`
-	SpecializationWeight float32  `url:"ws,omitempty"`
-	AcceptanceWeight     float32  `url:"wa,omitempty"`
-	DetailWeight         float32  `url:"wd,omitempty"`
+	Terms          []string            `url:"input"`
+	Ontologies     []string            `url:"ontologies,omitempty"`
+	InputType      RecommendInputType  `url:"input_type,omitempty"`
+	OutputType     RecommendOutputType `url:"output_type,omitempty"`
+	MaxElementsSet int                 `url:"max_elements_set,omitempty"`
+
+	// Weights of the four criteria. If any is set all four are sent, so a
+	// zero weight disables its criterion. If none is set the server
+	// defaults are used.
+	CoverageWeight       float32 `url:"-"`
+	SpecializationWeight float32 `url:"-"`
+	AcceptanceWeight     float32 `url:"-"`
+	DetailWeight         float32 `url:"-"`
+}
+
+type recommendWeights struct {
+	Coverage       float32 `url:"wc"`
+	Specialization float32 `url:"ws"`
+	Acceptance     float32 `url:"wa"`
+	Detail         float32 `url:"wd"`
+}
+
+// recommendParams are the query parameters of a recommendation. The weights
+// are only included when set.
+type recommendParams struct {
+	*RecommendOptions
+	*recommendWeights `url:",omitempty"`
+}
+
+func (o *RecommendOptions) params() *recommendParams {
+	p := &recommendParams{RecommendOptions: o}
+
+	if o.hasWeights() {
+		p.recommendWeights = &recommendWeights{
+			Coverage:       o.CoverageWeight,
+			Specialization: o.SpecializationWeight,
+			Acceptance:     o.AcceptanceWeight,
+			Detail:         o.DetailWeight,
+		}
+	}
+
+	return p
+}
+
+func (o *RecommendOptions) hasWeights() bool {
+	return o.CoverageWeight != 0 || o.SpecializationWeight != 0 || o.AcceptanceWeight != 0 || o.DetailWeight != 0
 }
 
 func DefaultRecommendOptions() *RecommendOptions {
 	return &RecommendOptions{
 		BaseOptions:    *DefaultBaseOptions(),
-		InputType:      1,
-		OutputType:     1,
+		InputType:      RecommendText,
+		OutputType:     RecommendSingle,
 		MaxElementsSet: 3,
 	}
 }
 
+// weights returns the configured weights, falling back to the server
+// defaults if none are set.
+func (o *RecommendOptions) weights() (wc, ws, wa, wd float64) {
+	if !o.hasWeights() {
+		return DefaultCoverageWeight, DefaultSpecializationWeight, DefaultAcceptanceWeight, DefaultDetailWeight
+	}
+
+	return float64(o.CoverageWeight), float64(o.SpecializationWeight), float64(o.AcceptanceWeight), float64(o.DetailWeight)
+}
+
+func (o *RecommendOptions) Validate() error {
+	if len(o.Terms) == 0 {
+		return errors.New("at least one term is required")
+	}
+
+	switch o.InputType {
+	case 0, RecommendText, RecommendKeywords:
+	default:
+		return fmt.Errorf("invalid input type: %d", o.InputType)
+	}
+
+	switch o.OutputType {
+	case 0, RecommendSingle:
+	case RecommendSets:
+		if o.MaxElementsSet != 0 && (o.MaxElementsSet < 2 || o.MaxElementsSet > 4) {
+			return fmt.Errorf("max elements per set must be between 2 and 4, got %d", o.MaxElementsSet)
+		}
+	default:
+		return fmt.Errorf("invalid output type: %d", o.OutputType)
+	}
+
+	ws := []float32{o.CoverageWeight, o.SpecializationWeight, o.AcceptanceWeight, o.DetailWeight}
+
+	var sum float64
+	for _, w := range ws {
+		if w < 0 {
+			return errors.New("weights cannot be negative")
+		}
+		sum += float64(w)
+	}
+
+	// All zero means the server defaults are used.
+	if sum != 0 && math.Abs(sum-1) > 0.01 {
+		return fmt.Errorf("weights must sum to 1, got %.2f", sum)
+	}
+
+	return nil
+}
+
 type RecommendResult struct {
 	EvaluationScore float64 `json:"evaluationScore"`
 	Ontologies      []struct {
@@ -31,10 +145,10 @@ type RecommendResult struct {
 		Type    string `json:"@type"`
 	} `json:"ontologies"`
 	CoverageResult struct {
-		Score              int `json:"score"`
-		NormalizedScore    int `json:"normalizedScore"`
-		NumberTermsCovered int `json:"numberTermsCovered"`
-		NumberWordsCovered int `json:"numberWordsCovered"`
+		Score              float64 `json:"score"`
+		NormalizedScore    float64 `json:"normalizedScore"`
+		NumberTermsCovered int     `json:"numberTermsCovered"`
+		NumberWordsCovered int     `json:"numberWordsCovered"`
 		Annotations        []struct {
 			From           int    `json:"from"`
 			To             int    `json:"to"`
@@ -49,17 +163,67 @@ type RecommendResult struct {
 	} `json:"coverageResult"`
 	SpecializationResult struct {
 		Score           float64 `json:"score"`
-		NormalizedScore int     `json:"normalizedScore"`
+		NormalizedScore float64 `json:"normalizedScore"`
 	} `json:"specializationResult"`
 	AcceptanceResult struct {
 		NormalizedScore float64 `json:"normalizedScore"`
 		BioportalScore  float64 `json:"bioportalScore"`
-		UmlsScore       int     `json:"umlsScore"`
+		UmlsScore       float64 `json:"umlsScore"`
 	} `json:"acceptanceResult"`
 	DetailResult struct {
 		NormalizedScore  float64 `json:"normalizedScore"`
-		DefinitionsScore int     `json:"definitionsScore"`
-		SynonymsScore    int     `json:"synonymsScore"`
+		DefinitionsScore float64 `json:"definitionsScore"`
+		SynonymsScore    float64 `json:"synonymsScore"`
 		PropertiesScore  float64 `json:"propertiesScore"`
 	} `json:"detailResult"`
 }
+
+// ScoreComponent is one weighted part of a recommendation's composite score.
+type ScoreComponent struct {
+	Name            string
+	Weight          float64
+	NormalizedScore float64
+	Contribution    float64
+}
+
+type ScoreExplanation struct {
+	Components []ScoreComponent
+
+	// Composite is the sum of the weighted components.
+	Composite float64
+
+	// EvaluationScore is the score reported by the server.
+	EvaluationScore float64
+}
+
+func (e *ScoreExplanation) String() string {
+	s := fmt.Sprintf("score %.3f", e.Composite)
+	for _, c := range e.Components {
+		s += fmt.Sprintf("\n  %-14s %.2f x %.3f = %.3f", c.Name, c.Weight, c.NormalizedScore, c.Contribution)
+	}
+	return s
+}
+
+// Explain breaks the result's score into its coverage, specialization,
+// acceptance and detail parts using the weights in opts.
+func (r *RecommendResult) Explain(opts RecommendOptions) *ScoreExplanation {
+	wc, ws, wa, wd := opts.weights()
+
+	e := &ScoreExplanation{
+		Components: []ScoreComponent{
+			{Name: "coverage", Weight: wc, NormalizedScore: r.CoverageResult.NormalizedScore},
+			{Name: "specialization", Weight: ws, NormalizedScore: r.SpecializationResult.NormalizedScore},
+			{Name: "acceptance", Weight: wa, NormalizedScore: r.AcceptanceResult.NormalizedScore},
+			{Name: "detail", Weight: wd, NormalizedScore: r.DetailResult.NormalizedScore},
+		},
+		EvaluationScore: r.EvaluationScore,
+	}
+
+	for i := range e.Components {
+		c := &e.Components[i]
+		c.Contribution = c.Weight * c.NormalizedScore
+		e.Composite += c.Contribution
+	}
+
+	return e
+}
@@ -0,0 +1,83 @@
+package bioportal
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/go-querystring/query"
+)
+
+func TestRecommendValidate(t *testing.T) {
+	opts := DefaultRecommendOptions()
+	opts.Terms = []string{"melanoma"}
+
+	if err := opts.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	opts.CoverageWeight = 0.5
+	if err := opts.Validate(); err == nil {
+		t.Error("expected error for weights not summing to 1")
+	}
+
+	opts.SpecializationWeight = 0.2
+	opts.AcceptanceWeight = 0.2
+	opts.DetailWeight = 0.1
+	if err := opts.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	// An explicit zero weight is sent and used when explaining.
+	opts.CoverageWeight = 0.6
+	opts.SpecializationWeight = 0.2
+	opts.AcceptanceWeight = 0.2
+	opts.DetailWeight = 0
+	if err := opts.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	v, err := query.Values(opts.params())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v.Get("wd") != "0" || v.Get("wc") != "0.6" {
+		t.Errorf("unexpected weights in query: %s", v.Encode())
+	}
+
+	var r RecommendResult
+	if w := r.Explain(*opts).Components[3]; w.Name != "detail" || w.Weight != 0 {
+		t.Errorf("unexpected detail component %+v", w)
+	}
+
+	// No weights leaves them to the server.
+	v, err = query.Values(DefaultRecommendOptions().params())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := v["wd"]; ok {
+		t.Errorf("unexpected weights in query: %s", v.Encode())
+	}
+
+	opts.OutputType = RecommendSets
+	opts.MaxElementsSet = 5
+	if err := opts.Validate(); err == nil {
+		t.Error("expected error for max elements per set")
+	}
+}
+
+func TestRecommendExplain(t *testing.T) {
+	var r RecommendResult
+	r.EvaluationScore = 0.7
+	r.CoverageResult.NormalizedScore = 1
+	r.SpecializationResult.NormalizedScore = 0.5
+	r.AcceptanceResult.NormalizedScore = 0.5
+	r.DetailResult.NormalizedScore = 0
+
+	e := r.Explain(*DefaultRecommendOptions())
+
+	if math.Abs(e.Composite-0.7) > 1e-9 {
+		t.Errorf("expected composite 0.7, got %f", e.Composite)
+	}
+}
@@ -1,5 +1,31 @@
 package bioportal
 
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MaxSearchPagesize is the largest page size accepted by the search endpoint.
+const MaxSearchPagesize = 5000
+
+var (
+	cuiRe = regexp.MustCompile(`^C\d{7}$`)
+	tuiRe = regexp.MustCompile(`^T\d{3}$`)
+)
+
+// ValidationError aggregates all problems found with a set of options.
+type ValidationError []error
+
+func (e ValidationError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
 type SearchOptions struct {
 	BaseOptions
 
@@ -27,6 +53,180 @@ func DefaultSearchOptions() *SearchOptions {
 	}
 }
 
+// Validate checks the options without contacting the server. All problems
+// are returned together as a ValidationError.
+func (o *SearchOptions) Validate() error {
+	var errs ValidationError
+
+	if strings.TrimSpace(o.Query) == "" {
+		errs = append(errs, errors.New("query cannot be empty"))
+	}
+
+	// A zero page or pagesize is left out of the request, so the server
+	// default is used.
+	if o.Page < 0 {
+		errs = append(errs, fmt.Errorf("page cannot be negative, got %d", o.Page))
+	}
+
+	if o.Pagesize < 0 || o.Pagesize > MaxSearchPagesize {
+		errs = append(errs, fmt.Errorf("pagesize must be between 0 (server default) and %d, got %d", MaxSearchPagesize, o.Pagesize))
+	}
+
+	if o.Suggest && o.RequireExactMatch {
+		errs = append(errs, errors.New("suggest cannot be combined with require exact match"))
+	}
+
+	for _, cui := range o.CUI {
+		if !cuiRe.MatchString(cui) {
+			errs = append(errs, fmt.Errorf("invalid CUI %q", cui))
+		}
+	}
+
+	for _, tui := range o.SemanticTypes {
+		if !tuiRe.MatchString(tui) {
+			errs = append(errs, fmt.Errorf("invalid semantic type %q", tui))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// ValidateOntologies checks that every ontology acronym is in the catalog.
+func (o *SearchOptions) ValidateOntologies(catalog []*Ontology) error {
+	known := make(map[string]struct{}, len(catalog))
+	for _, ont := range catalog {
+		known[ont.Acronym] = struct{}{}
+	}
+
+	var errs ValidationError
+
+	for _, acr := range o.Ontologies {
+		if _, ok := known[acr]; !ok {
+			errs = append(errs, fmt.Errorf("unknown ontology %q", acr))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// SearchBuilder builds validated search options.
+//
+//	opts, err := NewSearch("melanoma").
+//		Ontologies("NCIT", "SNOMEDCT").
+//		SemanticTypes("T191").
+//		Pagesize(20).
+//		Build()
+type SearchBuilder struct {
+	opts    SearchOptions
+	catalog []*Ontology
+}
+
+func NewSearch(query string) *SearchBuilder {
+	b := &SearchBuilder{
+		opts: *DefaultSearchOptions(),
+	}
+	b.opts.Query = query
+	return b
+}
+
+// Catalog sets the ontology catalog used to check acronyms on Build.
+func (b *SearchBuilder) Catalog(onts []*Ontology) *SearchBuilder {
+	b.catalog = onts
+	return b
+}
+
+func (b *SearchBuilder) Ontologies(acronyms ...string) *SearchBuilder {
+	b.opts.Ontologies = append(b.opts.Ontologies, acronyms...)
+	return b
+}
+
+func (b *SearchBuilder) CUI(cuis ...string) *SearchBuilder {
+	b.opts.CUI = append(b.opts.CUI, cuis...)
+	return b
+}
+
+func (b *SearchBuilder) SemanticTypes(tuis ...string) *SearchBuilder {
+	b.opts.SemanticTypes = append(b.opts.SemanticTypes, tuis...)
+	return b
+}
+
+func (b *SearchBuilder) ExactMatch() *SearchBuilder {
+	b.opts.RequireExactMatch = true
+	return b
+}
+
+func (b *SearchBuilder) Suggest() *SearchBuilder {
+	b.opts.Suggest = true
+	return b
+}
+
+func (b *SearchBuilder) RequireDefinitions() *SearchBuilder {
+	b.opts.RequireDefinitions = true
+	return b
+}
+
+func (b *SearchBuilder) AlsoSearchProperties() *SearchBuilder {
+	b.opts.AlsoSearchProperties = true
+	return b
+}
+
+func (b *SearchBuilder) AlsoSearchViews() *SearchBuilder {
+	b.opts.AlsoSearchViews = true
+	return b
+}
+
+func (b *SearchBuilder) AlsoSearchObsolete() *SearchBuilder {
+	b.opts.AlsoSearchObsolete = true
+	return b
+}
+
+func (b *SearchBuilder) Include(fields ...string) *SearchBuilder {
+	b.opts.Include = strings.Join(fields, ",")
+	return b
+}
+
+func (b *SearchBuilder) Page(n int) *SearchBuilder {
+	b.opts.Page = n
+	return b
+}
+
+func (b *SearchBuilder) Pagesize(n int) *SearchBuilder {
+	b.opts.Pagesize = n
+	return b
+}
+
+// Build validates and returns the options. Errors from all checks are
+// aggregated into a single ValidationError.
+func (b *SearchBuilder) Build() (*SearchOptions, error) {
+	opts := b.opts
+
+	var errs ValidationError
+
+	if err := opts.Validate(); err != nil {
+		errs = append(errs, err.(ValidationError)...)
+	}
+
+	if b.catalog != nil {
+		if err := opts.ValidateOntologies(b.catalog); err != nil {
+			errs = append(errs, err.(ValidationError)...)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	return &opts, nil
+}
+
 type SearchResult struct {
 	Page      int         `json:"page"`
 	PageCount int         `json:"pageCount"`
@@ -36,18 +236,33 @@ type SearchResult struct {
 		NextPage string      `json:"nextPage"`
 		PrevPage interface{} `json:"prevPage"`
 	} `json:"links"`
-	Collection []struct {
-		PrefLabel    string   `json:"prefLabel"`
-		Synonym      []string `json:"synonym,omitempty"`
-		Cui          []string `json:"cui,omitempty"`
-		SemanticType []string `json:"semanticType,omitempty"`
-		Obsolete     bool     `json:"obsolete"`
-		MatchType    string   `json:"matchType"`
-		OntologyType string   `json:"ontologyType"`
-		Provisional  bool     `json:"provisional"`
-		ID           string   `json:"@id"`
-		Type         string   `json:"@type"`
-		Links        struct {
+	Collection []SearchClass `json:"collection"`
+}
+
+type SearchClass struct {
+	PrefLabel    string   `json:"prefLabel"`
+	Synonym      []string `json:"synonym,omitempty"`
+	Cui          []string `json:"cui,omitempty"`
+	SemanticType []string `json:"semanticType,omitempty"`
+	Obsolete     bool     `json:"obsolete"`
+	MatchType    string   `json:"matchType"`
+	OntologyType string   `json:"ontologyType"`
+	Provisional  bool     `json:"provisional"`
+	ID           string   `json:"@id"`
+	Type         string   `json:"@type"`
+	Links        struct {
+		Self        string `json:"self"`
+		Ontology    string `json:"ontology"`
+		Children    string `json:"children"`
+		Parents     string `json:"parents"`
+		Descendants string `json:"descendants"`
+		Ancestors   string `json:"ancestors"`
+		Instances   string `json:"instances"`
+		Tree        string `json:"tree"`
+		Notes       string `json:"notes"`
+		Mappings    string `json:"mappings"`
+		UI          string `json:"ui"`
+		Context     struct {
 			Self        string `json:"self"`
 			Ontology    string `json:"ontology"`
 			Children    string `json:"children"`
@@ -59,28 +274,26 @@ type SearchResult struct {
 			Notes       string `json:"notes"`
 			Mappings    string `json:"mappings"`
 			UI          string `json:"ui"`
-			Context     struct {
-				Self        string `json:"self"`
-				Ontology    string `json:"ontology"`
-				Children    string `json:"children"`
-				Parents     string `json:"parents"`
-				Descendants string `json:"descendants"`
-				Ancestors   string `json:"ancestors"`
-				Instances   string `json:"instances"`
-				Tree        string `json:"tree"`
-				Notes       string `json:"notes"`
-				Mappings    string `json:"mappings"`
-				UI          string `json:"ui"`
-			} `json:"@context"`
-		} `json:"links"`
-		Context struct {
-			Vocab        string `json:"@vocab"`
-			PrefLabel    string `json:"prefLabel"`
-			Synonym      string `json:"synonym"`
-			Obsolete     string `json:"obsolete"`
-			SemanticType string `json:"semanticType"`
-			Cui          string `json:"cui"`
 		} `json:"@context"`
-		Definition []string `json:"definition,omitempty"`
-	} `json:"collection"`
+	} `json:"links"`
+	Context struct {
+		Vocab        string `json:"@vocab"`
+		PrefLabel    string `json:"prefLabel"`
+		Synonym      string `json:"synonym"`
+		Obsolete     string `json:"obsolete"`
+		SemanticType string `json:"semanticType"`
+		Cui          string `json:"cui"`
+	} `json:"@context"`
+	Definition []string `json:"definition,omitempty"`
+}
+
+// OntologyAcronym returns the acronym of the ontology the class belongs to,
+// taken from the ontology link.
+func (c *SearchClass) OntologyAcronym() string {
+	return lastSegment(c.Links.Ontology)
+}
+
+func lastSegment(u string) string {
+	u = strings.TrimRight(u, "/")
+	return u[strings.LastIndex(u, "/")+1:]
 }
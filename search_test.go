@@ -0,0 +1,53 @@
+package bioportal
+
+import "testing"
+
+func TestSearchBuilder(t *testing.T) {
+	catalog := []*Ontology{
+		{Acronym: "NCIT"},
+		{Acronym: "SNOMEDCT"},
+	}
+
+	opts, err := NewSearch("melanoma").
+		Catalog(catalog).
+		Ontologies("NCIT").
+		CUI("C0025202").
+		SemanticTypes("T191").
+		Pagesize(20).
+		Build()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if opts.Query != "melanoma" || opts.Pagesize != 20 {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+
+	_, err = NewSearch("").
+		Catalog(catalog).
+		Ontologies("NCIT", "XYZ").
+		CUI("0025202").
+		SemanticTypes("Neoplastic Process").
+		Pagesize(-1).
+		Build()
+
+	verr, ok := err.(ValidationError)
+	if !ok {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+
+	if len(verr) != 5 {
+		t.Errorf("expected 5 errors, got %d: %s", len(verr), verr)
+	}
+
+	// Zero leaves the server default.
+	if _, err = NewSearch("melanoma").Page(0).Pagesize(0).Build(); err != nil {
+		t.Errorf("expected zero page and pagesize to be valid, got %s", err)
+	}
+
+	_, err = NewSearch("melanoma").Page(-1).Pagesize(MaxSearchPagesize + 1).Build()
+	if verr, ok = err.(ValidationError); !ok || len(verr) != 2 {
+		t.Errorf("expected 2 validation errors, got %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package bioportal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSemanticTypes(t *testing.T) {
+	for _, s := range []string{"T047", "dsyn", "Disease or Syndrome", "disease or syndrome"} {
+		st := LookupSemanticType(s)
+		if st == nil || st.TUI != "T047" {
+			t.Errorf("lookup %q: got %v", s, st)
+		}
+	}
+
+	exp := []string{"T046", "T047", "T048", "T191", "T049", "T050"}
+	if res := ExpandSemanticTypes([]string{"patf"}); !reflect.DeepEqual(res, exp) {
+		t.Errorf("expected %v, got %v", exp, res)
+	}
+
+	if _, err := ResolveSemanticTypes([]string{"Neoplastic Process", "xyz"}); err == nil {
+		t.Error("expected error for unknown semantic type")
+	}
+}
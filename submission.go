@@ -0,0 +1,18 @@
+package bioportal
+
+type Submission struct {
+	SubmissionID        int      `json:"submissionId"`
+	Version             string   `json:"version"`
+	Released            string   `json:"released"`
+	CreationDate        string   `json:"creationDate"`
+	Status              string   `json:"status"`
+	SubmissionStatus    []string `json:"submissionStatus"`
+	HasOntologyLanguage string   `json:"hasOntologyLanguage"`
+	Description         string   `json:"description"`
+	ID                  string   `json:"@id"`
+	Type                string   `json:"@type"`
+	Links               struct {
+		Ontology string `json:"ontology"`
+		Download string `json:"download"`
+	} `json:"links"`
+}
@@ -0,0 +1,244 @@
+package bioportal
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSuperseded is returned by Suggester.Suggest when a newer call replaced
+// the pending one before it completed.
+var ErrSuperseded = errors.New("suggestion superseded by a newer query")
+
+type Suggestion struct {
+	Label     string `json:"label"`
+	ID        string `json:"id"`
+	Ontology  string `json:"ontology"`
+	MatchType string `json:"matchType"`
+}
+
+type SuggestOptions struct {
+	Ontologies []string
+
+	// Limit is the maximum number of suggestions returned.
+	Limit int
+
+	// Delay is how long a query waits for a newer one before being sent.
+	Delay time.Duration
+
+	// MinLength is the shortest prefix that is sent to the server.
+	MinLength int
+
+	// CacheSize is the number of prefixes whose results are kept.
+	CacheSize int
+}
+
+func DefaultSuggestOptions() *SuggestOptions {
+	return &SuggestOptions{
+		Limit:     10,
+		Delay:     150 * time.Millisecond,
+		MinLength: 2,
+		CacheSize: 256,
+	}
+}
+
+type suggestEntry struct {
+	res []Suggestion
+
+	// complete is true if the server returned fewer results than the limit,
+	// so longer prefixes can be answered by narrowing these results.
+	complete bool
+}
+
+// Suggester provides type-ahead suggestions. Each call to Suggest cancels the
+// previous pending call, so only the latest input reaches the server.
+type Suggester struct {
+	client *Client
+	opts   SuggestOptions
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	cache  map[string]*suggestEntry
+	keys   []string
+}
+
+func NewSuggester(c *Client, opts SuggestOptions) *Suggester {
+	return &Suggester{
+		client: c,
+		opts:   opts,
+		cache:  make(map[string]*suggestEntry),
+	}
+}
+
+func (s *Suggester) Suggest(ctx context.Context, prefix string) ([]Suggestion, error) {
+	key := strings.ToLower(strings.TrimSpace(prefix))
+	if len(key) < s.opts.MinLength {
+		return nil, nil
+	}
+
+	s.mu.Lock()
+
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+
+	if res, ok := s.lookup(key); ok {
+		s.mu.Unlock()
+		return res, nil
+	}
+
+	rctx, cancel := context.WithCancel(ctx)
+	s.cancel = cancel
+
+	s.mu.Unlock()
+
+	defer cancel()
+
+	res, complete, err := s.fetch(rctx, key)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if rctx.Err() != nil {
+			return nil, ErrSuperseded
+		}
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.store(key, &suggestEntry{res: res, complete: complete})
+	s.mu.Unlock()
+
+	return res, nil
+}
+
+func (s *Suggester) fetch(ctx context.Context, key string) ([]Suggestion, bool, error) {
+	if s.opts.Delay > 0 {
+		t := time.NewTimer(s.opts.Delay)
+		defer t.Stop()
+
+		select {
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		case <-t.C:
+		}
+	}
+
+	opts := DefaultSearchOptions()
+	opts.Query = key
+	opts.Suggest = true
+	opts.Ontologies = s.opts.Ontologies
+	opts.Pagesize = s.opts.Limit
+	opts.DisplayContext = false
+
+	res, err := s.client.WithContext(ctx).Search(*opts)
+	if err != nil {
+		return nil, false, err
+	}
+
+	sugs := make([]Suggestion, len(res.Collection))
+	for i, c := range res.Collection {
+		sugs[i] = Suggestion{
+			Label:     c.PrefLabel,
+			ID:        c.ID,
+			Ontology:  c.OntologyAcronym(),
+			MatchType: c.MatchType,
+		}
+	}
+
+	rankSuggestions(sugs)
+
+	complete := s.opts.Limit > 0 && len(sugs) < s.opts.Limit
+
+	return sugs, complete, nil
+}
+
+// lookup returns cached results for the key, either directly or by narrowing
+// the complete results of a shorter prefix.
+func (s *Suggester) lookup(key string) ([]Suggestion, bool) {
+	if e, ok := s.cache[key]; ok {
+		return e.res, true
+	}
+
+	for i := len(key) - 1; i >= s.opts.MinLength && i > 0; i-- {
+		e, ok := s.cache[key[:i]]
+		if !ok || !e.complete {
+			continue
+		}
+
+		return narrowSuggestions(e.res, key), true
+	}
+
+	return nil, false
+}
+
+func (s *Suggester) store(key string, e *suggestEntry) {
+	if s.opts.CacheSize <= 0 {
+		return
+	}
+
+	if _, ok := s.cache[key]; !ok {
+		s.keys = append(s.keys, key)
+	}
+	s.cache[key] = e
+
+	for len(s.keys) > s.opts.CacheSize {
+		delete(s.cache, s.keys[0])
+		s.keys = s.keys[1:]
+	}
+}
+
+var matchTypeRank = map[string]int{
+	"prefLabel": 0,
+	"synonym":   1,
+}
+
+// rankSuggestions orders suggestions by match type, preferred labels first,
+// keeping the server order within each type.
+func rankSuggestions(sugs []Suggestion) {
+	rank := func(t string) int {
+		if r, ok := matchTypeRank[t]; ok {
+			return r
+		}
+		return len(matchTypeRank)
+	}
+
+	sort.SliceStable(sugs, func(i, j int) bool {
+		return rank(sugs[i].MatchType) < rank(sugs[j].MatchType)
+	})
+}
+
+// narrowSuggestions filters results for a shorter prefix down to those that
+// could match key. Suggestions matched on something other than the preferred
+// label cannot be checked locally and are kept.
+func narrowSuggestions(sugs []Suggestion, key string) []Suggestion {
+	var res []Suggestion
+
+	for _, s := range sugs {
+		if s.MatchType != "prefLabel" || hasWordPrefix(strings.ToLower(s.Label), key) {
+			res = append(res, s)
+		}
+	}
+
+	return res
+}
+
+func hasWordPrefix(s, prefix string) bool {
+	if strings.HasPrefix(s, prefix) {
+		return true
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == ' ' || s[i] == '-' || s[i] == ',' || s[i] == '(' {
+			if strings.HasPrefix(s[i+1:], prefix) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,35 @@
+package bioportal
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSuggesterCache(t *testing.T) {
+	s := NewSuggester(nil, *DefaultSuggestOptions())
+
+	sugs := []Suggestion{
+		{Label: "Heart attack", MatchType: "synonym"},
+		{Label: "Heart", MatchType: "prefLabel"},
+		{Label: "Cardiac arrest", MatchType: "prefLabel"},
+		{Label: "Congestive heart failure", MatchType: "prefLabel"},
+	}
+
+	rankSuggestions(sugs)
+
+	if sugs[0].Label != "Heart" || sugs[3].Label != "Heart attack" {
+		t.Fatalf("unexpected ranking: %v", sugs)
+	}
+
+	s.store("he", &suggestEntry{res: sugs, complete: true})
+
+	// Served from the cached shorter prefix without a client.
+	res, err := s.Suggest(context.Background(), "Heart")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 3 {
+		t.Errorf("expected 3 suggestions, got %v", res)
+	}
+}
@@ -0,0 +1,92 @@
+package bioportal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// TreePaths returns every path from a root to the node with the given ID.
+// Each path starts with the root and ends with the node.
+func TreePaths(roots []Tree, id string) [][]*Tree {
+	var (
+		paths [][]*Tree
+		stack []*Tree
+	)
+
+	var walk func(t *Tree)
+	walk = func(t *Tree) {
+		stack = append(stack, t)
+
+		if t.ID == id {
+			p := make([]*Tree, len(stack))
+			copy(p, stack)
+			paths = append(paths, p)
+		} else {
+			for i := range t.Children {
+				walk(&t.Children[i])
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+	}
+
+	for i := range roots {
+		walk(&roots[i])
+	}
+
+	return paths
+}
+
+// PruneTree returns a copy of the tree containing only the nodes on a path
+// to the node with the given ID. The node's own children are kept.
+func PruneTree(roots []Tree, id string) []Tree {
+	var prune func(ts []Tree) []Tree
+	prune = func(ts []Tree) []Tree {
+		var res []Tree
+
+		for _, t := range ts {
+			if t.ID == id {
+				res = append(res, t)
+				continue
+			}
+
+			if children := prune(t.Children); len(children) > 0 {
+				t.Children = children
+				res = append(res, t)
+			}
+		}
+
+		return res
+	}
+
+	return prune(roots)
+}
+
+// RenderTree writes the tree as indented text, one label per line. Nodes
+// with children that were not expanded are marked with a "+".
+func RenderTree(w io.Writer, roots []Tree) error {
+	var render func(ts []Tree, depth int) error
+	render = func(ts []Tree, depth int) error {
+		for i := range ts {
+			t := &ts[i]
+
+			mark := ""
+			if t.HasChildren && len(t.Children) == 0 {
+				mark = " +"
+			}
+
+			if _, err := fmt.Fprintf(w, "%s%s%s\n", strings.Repeat("  ", depth), t.PrefLabel, mark); err != nil {
+				return err
+			}
+
+			if err := render(t.Children, depth+1); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return render(roots, 0)
+}
@@ -0,0 +1,44 @@
+package bioportal
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTree(t *testing.T) {
+	roots := []Tree{
+		{ID: "a", PrefLabel: "A", HasChildren: true, Children: []Tree{
+			{ID: "b", PrefLabel: "B", HasChildren: true},
+			{ID: "c", PrefLabel: "C", HasChildren: true, Children: []Tree{
+				{ID: "d", PrefLabel: "D"},
+			}},
+		}},
+		{ID: "e", PrefLabel: "E", HasChildren: true, Children: []Tree{
+			{ID: "d", PrefLabel: "D"},
+		}},
+	}
+
+	paths := TreePaths(roots, "d")
+	if len(paths) != 2 {
+		t.Fatalf("expected 2 paths, got %d", len(paths))
+	}
+
+	if len(paths[0]) != 3 || paths[0][1].ID != "c" {
+		t.Errorf("unexpected first path")
+	}
+
+	var buf bytes.Buffer
+	if err := RenderTree(&buf, PruneTree(roots, "d")); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := "A\n  C\n    D\nE\n  D\n"
+	if buf.String() != exp {
+		t.Errorf("expected:\n%s\ngot:\n%s", exp, buf.String())
+	}
+
+	// Pruning does not modify the original tree.
+	if len(roots[0].Children) != 2 {
+		t.Error("original tree was modified")
+	}
+}
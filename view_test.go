@@ -0,0 +1,83 @@
+package bioportal
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestViews(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ontologies/NCIT/views":
+			w.Write([]byte(`[{"acronym": "NCIT-NEOPLASM", "name": "Neoplasm view"}]`))
+		case "/ontologies/NCIT-NEOPLASM/classes":
+			if p := r.URL.Query().Get("page"); p != "2" {
+				t.Errorf("expected page 2, got %q", p)
+			}
+			w.Write([]byte(`{
+				"page": 2,
+				"pageCount": 3,
+				"prevPage": 1,
+				"nextPage": 3,
+				"collection": [
+					{"@id": "http://x/C1", "prefLabel": "Neoplasm", "cui": ["C0027651"]},
+					{"@id": "http://x/C2", "prefLabel": "Melanoma"}
+				]
+			}`))
+		case "/search":
+			q := r.URL.Query()
+			if q.Get("ontologies") != "NCIT-NEOPLASM" || q.Get("also_search_views") != "true" {
+				t.Errorf("unexpected search %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"page": 1, "pageCount": 1, "collection": [{"@id": "http://x/C2", "prefLabel": "Melanoma"}]}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	defer func(u string) { BaseURL = u }(BaseURL)
+	BaseURL = srv.URL
+
+	c := NewClient("key")
+
+	views, err := c.Views("NCIT")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(views) != 1 || views[0].Acronym != "NCIT-NEOPLASM" {
+		t.Fatalf("unexpected views %v", views)
+	}
+
+	opts := DefaultBaseOptions()
+	opts.Page = 2
+
+	// The collection of a page is a list of classes.
+	res, err := c.ViewClasses(views[0].Acronym, *opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Page != 2 || res.PageCount != 3 || len(res.Collection) != 2 {
+		t.Fatalf("unexpected page %+v", res)
+	}
+
+	if cl := res.Collection[0]; cl.ID != "http://x/C1" || cl.PrefLabel != "Neoplasm" || len(cl.CUIs()) != 1 {
+		t.Errorf("unexpected class %+v", cl)
+	}
+
+	so := DefaultSearchOptions()
+	so.Query = "melanoma"
+
+	sr, err := c.SearchView(views[0].Acronym, *so)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sr.Collection) != 1 || sr.Collection[0].PrefLabel != "Melanoma" {
+		t.Errorf("unexpected search result %+v", sr)
+	}
+}